@@ -0,0 +1,82 @@
+package pseudonymization
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Operation identifies the kind of operation recorded in an AuditEvent
+type Operation string
+
+const (
+	// OperationPseudonymize records the creation of a pseudonym
+	OperationPseudonymize Operation = "pseudonymize"
+
+	// OperationAnonymize records an irreversible anonymization by Anonymize
+	OperationAnonymize Operation = "anonymize"
+
+	// OperationRevert records a re-identification (decryption of the original value)
+	OperationRevert Operation = "revert"
+
+	// OperationTokenize records the issue of a token by Tokenize
+	OperationTokenize Operation = "tokenize"
+
+	// OperationDetokenize records a re-identification through Detokenize
+	OperationDetokenize Operation = "detokenize"
+
+	// OperationRectify links a corrected value to the one it replaced, see
+	// Repseudonymize
+	OperationRectify Operation = "rectify"
+)
+
+// AuditEvent describes a pseudonymization or re-identification for audit
+// trails. It never carries the original value.
+type AuditEvent struct {
+	Operation    Operation `json:"operation"`
+	OriginalHash string    `json:"original_hash_value,omitempty"`
+	PreviousHash string    `json:"previous_hash_value,omitempty"` // OperationRectify only
+	Pseudonym    string    `json:"client_id,omitempty"`
+	Purpose      string    `json:"purpose,omitempty"`
+	System       string    `json:"system,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// AuditLogger receives an AuditEvent for every successful Pseudonymize and
+// Revert call. Implementations must be safe for concurrent use.
+type AuditLogger interface {
+	LogEvent(ctx context.Context, event AuditEvent)
+}
+
+// NoopAuditLogger discards every event. It is the default AuditLogger.
+type NoopAuditLogger struct{}
+
+// LogEvent does nothing
+func (NoopAuditLogger) LogEvent(context.Context, AuditEvent) {}
+
+// JSONAuditLogger writes each event as a line of JSON to an io.Writer
+type JSONAuditLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAuditLogger creates an AuditLogger writing JSON lines to w
+func NewJSONAuditLogger(w io.Writer) *JSONAuditLogger {
+	return &JSONAuditLogger{enc: json.NewEncoder(w)}
+}
+
+// LogEvent writes event as a single JSON line. Write errors are ignored so
+// that a failing sink does not interrupt pseudonymization.
+func (l *JSONAuditLogger) LogEvent(_ context.Context, event AuditEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(event)
+}
+
+// audit stamps event with the current time and hands it to the audit logger
+func (s *Service) audit(ctx context.Context, event AuditEvent) {
+	event.Timestamp = s.clock().UTC()
+	s.auditLogger.LogEvent(ctx, event)
+}
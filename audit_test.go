@@ -0,0 +1,77 @@
+package pseudonymization
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingAuditLogger keeps every event in memory
+type recordingAuditLogger struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+func (l *recordingAuditLogger) LogEvent(_ context.Context, event AuditEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func TestAuditLogger(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithAuditLogger(logger))
+
+	result, err := svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	_, err = svc.RevertWithContext(result.EncryptedValue, "court-order", "legal")
+	assert.NoError(t, err)
+
+	// Failed operations are not recorded
+	_, err = svc.Revert("invalid")
+	assert.Error(t, err)
+
+	assert.Len(t, logger.events, 2)
+
+	pseudonymized := logger.events[0]
+	assert.Equal(t, OperationPseudonymize, pseudonymized.Operation)
+	assert.Equal(t, result.OriginalHash, pseudonymized.OriginalHash)
+	assert.Equal(t, result.Pseudonym, pseudonymized.Pseudonym)
+	assert.Equal(t, "billing", pseudonymized.Purpose)
+	assert.Equal(t, "erp", pseudonymized.System)
+	assert.False(t, pseudonymized.Timestamp.IsZero())
+
+	reverted := logger.events[1]
+	assert.Equal(t, OperationRevert, reverted.Operation)
+	assert.Equal(t, result.OriginalHash, reverted.OriginalHash)
+	assert.Equal(t, "court-order", reverted.Purpose)
+	assert.Equal(t, "legal", reverted.System)
+}
+
+func TestJSONAuditLogger(t *testing.T) {
+	var buf bytes.Buffer
+	svc := NewService(randomKey(t, 32), WithAuditLogger(NewJSONAuditLogger(&buf)))
+
+	result, err := svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event AuditEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, OperationPseudonymize, events[0].Operation)
+	assert.Equal(t, OperationRevert, events[1].Operation)
+	assert.NotContains(t, buf.String(), "52998224725")
+}
@@ -0,0 +1,173 @@
+package pseudonymization
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BatchError reports the per-item failures of a batch operation
+type BatchError struct {
+	// Errors has one entry per input value; entries are nil for values that
+	// were processed successfully
+	Errors []error
+}
+
+// Error summarizes the failed items
+func (e *BatchError) Error() string {
+	var failed []string
+	for i, err := range e.Errors {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("item %d: %v", i, err))
+		}
+	}
+	return fmt.Sprintf("%d of %d items failed: %s", len(failed), len(e.Errors), strings.Join(failed, "; "))
+}
+
+// PseudonymizeBatch pseudonymizes many values at once, reusing the service's
+// AES-GCM cipher for every value
+//
+// Parameters:
+// - values: The sensitive values to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+//   - Results in the same order as values; the entry for a value that failed is nil
+//   - *BatchError describing every failed value, or nil if all succeeded.
+//     A failing value does not abort the rest of the batch.
+func (s *Service) PseudonymizeBatch(values []string, purpose, system string) ([]*Result, error) {
+	results := make([]*Result, len(values))
+	errs := make([]error, len(values))
+	failed := false
+	opts := PseudonymizeOptions{Purpose: purpose, System: system}
+
+	for i, value := range values {
+		results[i], errs[i] = s.pseudonymizeBatchItem(value, opts)
+		if errs[i] != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		return results, &BatchError{Errors: errs}
+	}
+	return results, nil
+}
+
+// PseudonymizeAll pseudonymizes a group of related values, such as the name,
+// CPF and email of one person, as a unit: either every value is
+// pseudonymized or, on error, no Result is returned. Every value is
+// validated before the first one is encrypted, so an empty, oversized or
+// malformed field fails the group without any audit event; only a failure
+// while encrypting (e.g. a closed service) can leave audit events for the
+// fields processed before it.
+//
+// Parameters:
+// - values: The sensitive values to pseudonymize, by field name
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+//   - Results under the same keys as values
+//   - error of the first field that failed, in key order, prefixed with its
+//     key
+func (s *Service) PseudonymizeAll(values map[string]string, purpose, system string) (map[string]*Result, error) {
+	keys := slices.Sorted(maps.Keys(values))
+	for _, key := range keys {
+		if _, err := s.prepareBatchItem(values[key]); err != nil {
+			return nil, fmt.Errorf("field %s: %w", key, err)
+		}
+	}
+
+	results := make(map[string]*Result, len(values))
+	opts := PseudonymizeOptions{Purpose: purpose, System: system}
+	for _, key := range keys {
+		result, err := s.pseudonymizeBatchItem(values[key], opts)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", key, err)
+		}
+		results[key] = result
+	}
+	return results, nil
+}
+
+// Validate checks values the way PseudonymizeBatch would before encrypting
+// them, without hashing, encrypting or auditing anything: each value must be
+// valid UTF-8, within the maximum length (see WithMaxValueLength), non-empty
+// after normalization and, in strict mode, not a pseudonym. Bulk jobs can
+// call it first to report every bad row up front instead of failing midway.
+//
+// Passing validation does not guarantee that pseudonymization succeeds, as
+// encryption can still fail, e.g. once the service is closed.
+//
+// Parameters:
+// - values: The sensitive values to check
+//
+// Returns:
+//   - One entry per value: nil if the value is processable, otherwise the
+//     error PseudonymizeBatch would report for it
+func (s *Service) Validate(values []string) []error {
+	errs := make([]error, len(values))
+	for i, value := range values {
+		_, errs[i] = s.prepareBatchItem(value)
+	}
+	return errs
+}
+
+// pseudonymizeBatchItem pseudonymizes one value of PseudonymizeBatch
+func (s *Service) pseudonymizeBatchItem(value string, opts PseudonymizeOptions) (_ *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if value, err = s.prepareBatchItem(value); err != nil {
+		return nil, err
+	}
+	return s.newResult(context.Background(), value, s.newPseudonym(), opts)
+}
+
+// prepareBatchItem checks the length and encoding of value and returns it
+// normalized, or ErrEmptyValue if nothing is left (or, in strict mode,
+// ErrAlreadyPseudonymized for a UUID)
+func (s *Service) prepareBatchItem(value string) (string, error) {
+	if err := s.checkLength(len(value)); err != nil {
+		return "", err
+	}
+	value, err := s.normalizeInput(value)
+	if err != nil {
+		return "", err
+	}
+	if len(value) == 0 {
+		return "", ErrEmptyValue
+	}
+	if err := s.checkNotPseudonym(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// RevertBatch reverts many encrypted values at once, reusing the service's
+// ciphers for every value. A value that fails to decrypt (a corrupted row,
+// say) does not abort the rest of the batch, and every successful revert is
+// audited individually.
+//
+// Callers must check each entry of errs: a nil entry means results holds the
+// original value at that index, a non-nil one means results holds "".
+//
+// Parameters:
+// - encryptedValues: Base64-encoded encrypted values
+//
+// Returns:
+// - Original values in the same order as encryptedValues
+// - Errors in the same order as encryptedValues, nil for values that succeeded
+func (s *Service) RevertBatch(encryptedValues []string) (results []string, errs []error) {
+	results = make([]string, len(encryptedValues))
+	errs = make([]error, len(encryptedValues))
+
+	for i, encrypted := range encryptedValues {
+		results[i], errs[i] = s.revert(context.Background(), encrypted, RevertOptions{})
+	}
+	return results, errs
+}
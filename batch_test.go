@@ -0,0 +1,179 @@
+package pseudonymization
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizeBatch(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key)
+
+	values := []string{"52998224725", "user@example.com", "11144477735"}
+	results, err := svc.PseudonymizeBatch(values, "test", "test")
+	assert.NoError(t, err)
+	assert.Len(t, results, len(values))
+
+	for i, result := range results {
+		assert.Equal(t, svc.Hash(values[i]), result.OriginalHash)
+
+		original, err := svc.Revert(result.EncryptedValue)
+		assert.NoError(t, err)
+		assert.Equal(t, values[i], original)
+	}
+
+	// Failures are reported per item without aborting the batch
+	results, err = svc.PseudonymizeBatch([]string{"a", "", "c"}, "test", "test")
+	var batchErr *BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Len(t, batchErr.Errors, 3)
+	assert.NoError(t, batchErr.Errors[0])
+	assert.Error(t, batchErr.Errors[1])
+	assert.NoError(t, batchErr.Errors[2])
+	assert.NotNil(t, results[0])
+	assert.Nil(t, results[1])
+	assert.NotNil(t, results[2])
+}
+
+func TestPseudonymizeAll(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithAuditLogger(logger))
+
+	person := map[string]string{
+		"name":  "Maria da Silva",
+		"cpf":   "52998224725",
+		"email": "maria@example.com",
+	}
+	results, err := svc.PseudonymizeAll(person, "crm", "web")
+	assert.NoError(t, err)
+	assert.Len(t, results, len(person))
+	for field, value := range person {
+		assert.Equal(t, svc.Hash(value), results[field].OriginalHash)
+
+		original, err := svc.Decrypt(results[field].EncryptedValue)
+		assert.NoError(t, err)
+		assert.Equal(t, value, original)
+	}
+	assert.Len(t, logger.events, 3)
+
+	// One invalid field fails the group before anything is pseudonymized
+	person["email"] = ""
+	results, err = svc.PseudonymizeAll(person, "crm", "web")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+	assert.Contains(t, err.Error(), "field email")
+	assert.Nil(t, results)
+	assert.Len(t, logger.events, 3)
+
+	results, err = svc.PseudonymizeAll(nil, "crm", "web")
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	svc.Close()
+	_, err = svc.PseudonymizeAll(map[string]string{"cpf": "52998224725"}, "crm", "web")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+}
+
+func TestValidate(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithAuditLogger(logger), WithMaxValueLength(40),
+		WithNormalizer(NormalizeWhitespace), WithStrictMode())
+
+	errs := svc.Validate([]string{
+		"52998224725",
+		"",
+		"   ",
+		"Jos\xe9",
+		"this value is far too long for the configured limit",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	})
+	assert.Len(t, errs, 6)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrEmptyValue)
+	assert.ErrorIs(t, errs[2], ErrEmptyValue)
+	assert.ErrorIs(t, errs[3], ErrInvalidUTF8)
+	assert.ErrorIs(t, errs[4], ErrValueTooLarge)
+	assert.ErrorIs(t, errs[5], ErrAlreadyPseudonymized)
+
+	// Nothing is pseudonymized or audited
+	assert.Empty(t, logger.events)
+	assert.Empty(t, svc.Validate(nil))
+}
+
+func TestRevertBatch(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	logger := &recordingAuditLogger{}
+	svc := NewService(key, WithAuditLogger(logger))
+
+	values := []string{"52998224725", "user@example.com", "11144477735"}
+	results, err := svc.PseudonymizeBatch(values, "test", "test")
+	assert.NoError(t, err)
+
+	encrypted := []string{results[0].EncryptedValue, "corrupted!", results[1].EncryptedValue, "", results[2].EncryptedValue}
+	originals, errs := svc.RevertBatch(encrypted)
+	assert.Len(t, originals, len(encrypted))
+	assert.Len(t, errs, len(encrypted))
+
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrMalformedCiphertext)
+	assert.NoError(t, errs[2])
+	assert.ErrorIs(t, errs[3], ErrNotReversible)
+	assert.NoError(t, errs[4])
+	assert.Equal(t, []string{values[0], "", values[1], "", values[2]}, originals)
+
+	// Three pseudonymizations and three successful reverts
+	assert.Len(t, logger.events, 6)
+
+	originals, errs = svc.RevertBatch(nil)
+	assert.Empty(t, originals)
+	assert.Empty(t, errs)
+}
+
+func benchmarkValues(n int) []string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = fmt.Sprintf("%011d", i)
+	}
+	return values
+}
+
+func BenchmarkPseudonymizeLoop(b *testing.B) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	svc := NewService(key)
+	values := benchmarkValues(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, value := range values {
+			if _, err := svc.Pseudonymize(value, "bench", "bench"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkPseudonymizeBatch(b *testing.B) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	svc := NewService(key)
+	values := benchmarkValues(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.PseudonymizeBatch(values, "bench", "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
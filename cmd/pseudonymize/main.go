@@ -0,0 +1,306 @@
+// Command pseudonymize pseudonymizes (or reverts) columns of a CSV file.
+//
+// Rows are streamed, so files of any size can be processed. The first row
+// must be a header; columns are selected by name.
+//
+// Usage:
+//
+//	pseudonymize -columns cpf,email [-emit pseudonym,hash,ciphertext] < in.csv > out.csv
+//	pseudonymize -revert -columns cpf_encrypted < out.csv > in.csv
+//
+// Each pseudonymized column is replaced by one column per emitted artifact,
+// named <column>_pseudonym, <column>_hash and <column>_encrypted. In revert
+// mode each selected column is replaced in place by its decrypted value.
+// Empty cells are left empty.
+//
+// The AES key is read from the environment variable named by -key-env
+// (default PSEUDONYMIZATION_KEY) or from the file given by -key-file, encoded
+// as hex or base64.
+package main
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/raywall/pseudonymization-lgpd-tools"
+)
+
+// Suffixes of the columns produced for each pseudonymized column
+const (
+	suffixPseudonym = "_pseudonym"
+	suffixHash      = "_hash"
+	suffixEncrypted = "_encrypted"
+)
+
+// config holds the parsed command line
+type config struct {
+	columns []string
+	emit    map[string]bool
+	revert  bool
+	purpose string
+	system  string
+	keyEnv  string
+	keyFile string
+	input   string
+	output  string
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Getenv); err != nil {
+		fmt.Fprintln(os.Stderr, "pseudonymize:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command with the given arguments and I/O, so that it can
+// be tested without a process
+func run(args []string, stdin io.Reader, stdout io.Writer, getenv func(string) string) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	key, err := loadKey(cfg, getenv)
+	if err != nil {
+		return err
+	}
+	svc, err := pseudonymization.NewServiceWithError(key)
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	in, out := stdin, stdout
+	if cfg.input != "" {
+		f, err := os.Open(cfg.input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if cfg.output != "" {
+		f, err := os.Create(cfg.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return processCSV(svc, cfg, in, out)
+}
+
+// parseFlags parses and validates the command line
+func parseFlags(args []string) (*config, error) {
+	fs := flag.NewFlagSet("pseudonymize", flag.ContinueOnError)
+	columns := fs.String("columns", "", "comma-separated names of the columns to process (required)")
+	emit := fs.String("emit", "pseudonym,ciphertext", "comma-separated artifacts to emit: pseudonym, hash, ciphertext")
+	revert := fs.Bool("revert", false, "decrypt the selected columns instead of pseudonymizing them")
+	purpose := fs.String("purpose", "csv-export", "purpose recorded for the pseudonymization")
+	system := fs.String("system", "pseudonymize-cli", "system recorded for the pseudonymization")
+	keyEnv := fs.String("key-env", "PSEUDONYMIZATION_KEY", "environment variable holding the hex or base64 key")
+	keyFile := fs.String("key-file", "", "file holding the hex or base64 key (overrides -key-env)")
+	input := fs.String("in", "", "input CSV file (default stdin)")
+	output := fs.String("out", "", "output CSV file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := &config{
+		columns: splitList(*columns),
+		emit:    make(map[string]bool),
+		revert:  *revert,
+		purpose: *purpose,
+		system:  *system,
+		keyEnv:  *keyEnv,
+		keyFile: *keyFile,
+		input:   *input,
+		output:  *output,
+	}
+	if len(cfg.columns) == 0 {
+		return nil, errors.New("-columns is required")
+	}
+
+	for _, artifact := range splitList(*emit) {
+		switch artifact {
+		case "pseudonym", "hash", "ciphertext":
+			cfg.emit[artifact] = true
+		default:
+			return nil, fmt.Errorf("unknown -emit artifact %q", artifact)
+		}
+	}
+	if !cfg.revert && len(cfg.emit) == 0 {
+		return nil, errors.New("-emit must name at least one artifact")
+	}
+	return cfg, nil
+}
+
+// loadKey reads the key from -key-file or the -key-env variable
+func loadKey(cfg *config, getenv func(string) string) ([]byte, error) {
+	var encoded string
+	if cfg.keyFile != "" {
+		data, err := os.ReadFile(cfg.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	} else {
+		encoded = getenv(cfg.keyEnv)
+		if encoded == "" {
+			return nil, fmt.Errorf("no key: set %s or use -key-file", cfg.keyEnv)
+		}
+	}
+
+	return decodeKey(strings.TrimSpace(encoded))
+}
+
+// decodeKey decodes a hex or base64 key
+func decodeKey(encoded string) ([]byte, error) {
+	if key, err := hex.DecodeString(encoded); err == nil {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("key must be hex or base64 encoded")
+}
+
+// processCSV streams rows from in to out, transforming the selected columns
+func processCSV(svc *pseudonymization.Service, cfg *config, in io.Reader, out io.Writer) error {
+	r := csv.NewReader(in)
+	w := csv.NewWriter(out)
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return errors.New("input is empty")
+	}
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[int]bool, len(cfg.columns))
+	for _, name := range cfg.columns {
+		index := indexOf(header, name)
+		if index < 0 {
+			return fmt.Errorf("column %q not found in header", name)
+		}
+		selected[index] = true
+	}
+
+	if err := w.Write(transformHeader(cfg, header, selected)); err != nil {
+		return err
+	}
+
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		row, err := transformRow(svc, cfg, record, selected)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// transformHeader returns the output header
+func transformHeader(cfg *config, header []string, selected map[int]bool) []string {
+	out := make([]string, 0, len(header)+2*len(selected))
+	for i, name := range header {
+		if !selected[i] || cfg.revert {
+			out = append(out, name)
+			continue
+		}
+		if cfg.emit["pseudonym"] {
+			out = append(out, name+suffixPseudonym)
+		}
+		if cfg.emit["hash"] {
+			out = append(out, name+suffixHash)
+		}
+		if cfg.emit["ciphertext"] {
+			out = append(out, name+suffixEncrypted)
+		}
+	}
+	return out
+}
+
+// transformRow pseudonymizes or reverts the selected cells of record
+func transformRow(svc *pseudonymization.Service, cfg *config, record []string, selected map[int]bool) ([]string, error) {
+	out := make([]string, 0, len(record)+2*len(selected))
+	for i, value := range record {
+		if !selected[i] {
+			out = append(out, value)
+			continue
+		}
+
+		if cfg.revert {
+			if value != "" {
+				original, err := svc.Revert(value)
+				if err != nil {
+					return nil, err
+				}
+				value = original
+			}
+			out = append(out, value)
+			continue
+		}
+
+		var result pseudonymization.Result
+		if value != "" {
+			r, err := svc.Pseudonymize(value, cfg.purpose, cfg.system)
+			if err != nil {
+				return nil, err
+			}
+			result = *r
+		}
+		if cfg.emit["pseudonym"] {
+			out = append(out, result.Pseudonym)
+		}
+		if cfg.emit["hash"] {
+			out = append(out, result.OriginalHash)
+		}
+		if cfg.emit["ciphertext"] {
+			out = append(out, result.EncryptedValue)
+		}
+	}
+	return out, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// indexOf returns the position of name in header, or -1
+func indexOf(header []string, name string) int {
+	for i, column := range header {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEnv(t *testing.T) func(string) string {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	encoded := hex.EncodeToString(key)
+
+	return func(name string) string {
+		if name == "PSEUDONYMIZATION_KEY" {
+			return encoded
+		}
+		return ""
+	}
+}
+
+func readCSV(t *testing.T, data string) [][]string {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	assert.NoError(t, err)
+	return records
+}
+
+func TestPseudonymizeAndRevertCSV(t *testing.T) {
+	getenv := testEnv(t)
+	input := "id,cpf,name\n1,52998224725,Maria\n2,,João\n"
+
+	var pseudonymized bytes.Buffer
+	err := run([]string{"-columns", "cpf", "-emit", "pseudonym,hash,ciphertext"}, strings.NewReader(input), &pseudonymized, getenv)
+	assert.NoError(t, err)
+
+	records := readCSV(t, pseudonymized.String())
+	assert.Equal(t, []string{"id", "cpf_pseudonym", "cpf_hash", "cpf_encrypted", "name"}, records[0])
+	assert.Len(t, records, 3)
+	assert.Len(t, records[1][1], 36)
+	assert.Len(t, records[1][2], 64)
+	assert.NotContains(t, pseudonymized.String(), "52998224725")
+	assert.Equal(t, []string{"2", "", "", "", "João"}, records[2])
+
+	var reverted bytes.Buffer
+	err = run([]string{"-revert", "-columns", "cpf_encrypted"}, &pseudonymized, &reverted, getenv)
+	assert.NoError(t, err)
+
+	records = readCSV(t, reverted.String())
+	assert.Equal(t, "52998224725", records[1][3])
+	assert.Equal(t, "", records[2][3])
+}
+
+func TestKeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	assert.NoError(t, os.WriteFile(keyFile, []byte("AAECAwQFBgcICQoLDA0ODw==\n"), 0600))
+
+	var out bytes.Buffer
+	err := run([]string{"-key-file", keyFile, "-columns", "cpf"}, strings.NewReader("cpf\n52998224725\n"), &out, func(string) string { return "" })
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cpf_pseudonym", "cpf_encrypted"}, readCSV(t, out.String())[0])
+}
+
+func TestRunErrors(t *testing.T) {
+	getenv := testEnv(t)
+	input := "id,cpf\n1,52998224725\n"
+
+	testCases := []struct {
+		name string
+		args []string
+		env  func(string) string
+	}{
+		{"missing columns", []string{}, getenv},
+		{"unknown column", []string{"-columns", "email"}, getenv},
+		{"unknown artifact", []string{"-columns", "cpf", "-emit", "salt"}, getenv},
+		{"missing key", []string{"-columns", "cpf"}, func(string) string { return "" }},
+		{"invalid key", []string{"-columns", "cpf"}, func(string) string { return "not a key!" }},
+		{"revert of plaintext", []string{"-revert", "-columns", "cpf"}, getenv},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			assert.Error(t, run(tc.args, strings.NewReader(input), &out, tc.env))
+		})
+	}
+}
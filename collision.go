@@ -0,0 +1,64 @@
+package pseudonymization
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CollisionChecker records which value each deterministic pseudonym was
+// derived from (see WithCollisionChecker). Implementations must be safe for
+// concurrent use. Check should return an error wrapping ErrPseudonymCollision
+// when pseudonym was already recorded for a different originalHash.
+//
+// A collision of UUID v5 pseudonyms requires a SHA-1 collision over the
+// namespace and value (or its HMAC), so it is not expected to ever happen;
+// the checker gives auditors evidence that one would be detected. Backing it
+// with a shared store extends the check across processes.
+type CollisionChecker interface {
+	Check(pseudonym, originalHash string) error
+}
+
+// MemoryCollisionChecker is an in-memory CollisionChecker. It keeps one entry
+// per distinct value, so it suits bounded datasets and tests; its contents
+// are lost when the process exits.
+type MemoryCollisionChecker struct {
+	mu     sync.Mutex
+	hashes map[string]string
+}
+
+// NewMemoryCollisionChecker creates an empty MemoryCollisionChecker
+func NewMemoryCollisionChecker() *MemoryCollisionChecker {
+	return &MemoryCollisionChecker{hashes: make(map[string]string)}
+}
+
+// Check records originalHash for pseudonym, or fails if pseudonym was
+// already recorded for another hash
+func (c *MemoryCollisionChecker) Check(pseudonym, originalHash string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, ok := c.hashes[pseudonym]; ok && existing != originalHash {
+		return fmt.Errorf("%w: %s", ErrPseudonymCollision, pseudonym)
+	}
+	c.hashes[pseudonym] = originalHash
+	return nil
+}
+
+// Len returns the number of pseudonyms recorded
+func (c *MemoryCollisionChecker) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.hashes)
+}
+
+// checkCollision hands a deterministic pseudonym and the unsalted hash of its
+// value to the configured CollisionChecker, if any
+func (s *Service) checkCollision(pseudonym, value string) error {
+	if s.collisionChecker == nil {
+		return nil
+	}
+	hash, err := s.originalHash(value)
+	if err != nil {
+		return err
+	}
+	return s.collisionChecker.Check(pseudonym, hash)
+}
@@ -0,0 +1,34 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollisionChecker(t *testing.T) {
+	checker := NewMemoryCollisionChecker()
+	svc := NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)), WithCollisionChecker(checker), WithSaltedHash())
+
+	first, err := svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.NoError(t, err)
+	again, err := svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, first.Pseudonym, again.Pseudonym)
+	_, err = svc.PseudonymizeDeterministic("11144477735", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, checker.Len())
+
+	// Random pseudonyms are not recorded
+	_, err = svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, checker.Len())
+
+	// Simulate another value having produced the pseudonym first
+	colliding := NewMemoryCollisionChecker()
+	assert.NoError(t, colliding.Check(first.Pseudonym, "another value's hash"))
+	svc = NewService(randomKey(t, 32), WithHMACKey(svc.ring.hmacKey), WithCollisionChecker(colliding))
+	_, err = svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrPseudonymCollision)
+	assert.Contains(t, err.Error(), first.Pseudonym)
+}
@@ -0,0 +1,69 @@
+package pseudonymization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raywall/pseudonymization-lgpd-tools/utils"
+)
+
+// syntheticCPFPrefix is the formatted prefix of the synthetic CPFs generated
+// by utils.GenerateSyntheticCPF
+const syntheticCPFPrefix = "999."
+
+// PseudonymizeCPFFormatPreserving pseudonymizes a CPF using a valid synthetic
+// CPF (999.XXX.XXX-XX, see utils.GenerateSyntheticCPF) as the pseudonym, so
+// it fits columns and validators that demand an 11-digit CPF. The real CPF is
+// still hashed and encrypted: re-identify a record by looking it up by
+// OriginalHash, or Revert its EncryptedValue, which returns the CPF digits
+// without punctuation.
+//
+// Warning: unlike a UUID, the pseudonym reveals that the original value is a
+// CPF and its length. Synthetic pseudonyms are random, so they are not
+// deterministic, and they may collide with other synthetic pseudonyms; they
+// must never be mistaken for real CPFs.
+//
+// Parameters:
+// - cpf: The CPF to pseudonymize (formatted or unformatted)
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Result whose Pseudonym is a formatted synthetic CPF
+// - error: ErrInvalidCPF if cpf is not a valid CPF
+func (s *Service) PseudonymizeCPFFormatPreserving(cpf, purpose, system string) (_ *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if err = s.checkLength(len(cpf)); err != nil {
+		return nil, err
+	}
+	if len(cpf) == 0 {
+		return nil, ErrEmptyValue
+	}
+	if !utils.IsValidCPF(cpf) {
+		return nil, ErrInvalidCPF
+	}
+	digits := strings.Map(keepDigits, cpf)
+
+	pseudonym, err := utils.GenerateSyntheticCPFFromReader(s.ring.random)
+	if err != nil {
+		return nil, fmt.Errorf("pseudonym generation failed: %w", err)
+	}
+
+	return s.newResult(context.Background(), digits, pseudonym, PseudonymizeOptions{Purpose: purpose, System: system})
+}
+
+// isSyntheticCPF reports whether value is a formatted synthetic CPF
+func isSyntheticCPF(value string) bool {
+	return len(value) == 14 && strings.HasPrefix(value, syntheticCPFPrefix) && utils.IsValidCPF(value)
+}
+
+// keepDigits is a strings.Map function dropping every non-digit rune
+func keepDigits(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+	return -1
+}
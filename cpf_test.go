@@ -0,0 +1,39 @@
+package pseudonymization
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/raywall/pseudonymization-lgpd-tools/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizeCPFFormatPreserving(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.PseudonymizeCPFFormatPreserving("529.982.247-25", "billing", "erp")
+	assert.NoError(t, err)
+
+	// The pseudonym is a valid, formatted synthetic CPF
+	assert.True(t, utils.IsValidCPF(result.Pseudonym))
+	assert.True(t, strings.HasPrefix(result.Pseudonym, "999."))
+	assert.Len(t, result.Pseudonym, 14)
+
+	// Re-identification by hash and by reverting the ciphertext
+	assert.Equal(t, svc.Hash("52998224725"), result.OriginalHash)
+	original, err := svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Results survive a JSON round trip
+	data, err := result.ToJSON()
+	assert.NoError(t, err)
+	decoded, err := ResultFromJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, result, decoded)
+
+	_, err = svc.PseudonymizeCPFFormatPreserving("529.982.247-26", "billing", "erp")
+	assert.ErrorIs(t, err, ErrInvalidCPF)
+	_, err = svc.PseudonymizeCPFFormatPreserving("", "billing", "erp")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+}
@@ -12,13 +12,16 @@
 // Key Features:
 // - SHA-256 hashing for secure reference
 // - AES-GCM encryption for reversible pseudonymization
-// - UUID v4 generation as pseudonyms
+// - UUID v4 generation as pseudonyms (or UUID v7 and ULID, see WithPseudonymGenerator)
 // - Complete agnosticism of storage/transport layer
 //
 // Basic Usage Example:
 //
-//	// Create a new service with encryption key
+//	// Create a new service with a random encryption key
 //	key := make([]byte, 32) // In production, use proper key management
+//	if _, err := rand.Read(key); err != nil {
+//	    log.Fatal(err)
+//	}
 //	svc := pseudonymization.NewService(key)
 //
 //	// Pseudonymize a value (e.g., CPF)
@@ -36,4 +39,33 @@
 //	}
 //
 //	fmt.Printf("Original value: %s\n", original)
+//
+// Keyed Hashing:
+//
+// A plain SHA-256 of a low-entropy value such as a CPF can be reproduced by
+// anyone who guesses the value. Supplying a separate secret with WithHMACKey
+// makes Pseudonymize store an HMAC-SHA256 in OriginalHash instead:
+//
+//	svc := pseudonymization.NewService(key, pseudonymization.WithHMACKey(hmacKey))
+//	ref := svc.HashKeyed("12345678901")
+//
+// Migrating from plain SHA-256: existing OriginalHash values cannot be converted
+// without the original data. Pass each stored EncryptedValue to Rehash, which
+// returns its HashKeyed without exposing the plaintext, and store the new hash
+// alongside the old one. Keep
+// looking records up by Hash until every record carries a keyed hash, then drop
+// the plain hashes.
+//
+// Passphrase Keys:
+//
+// DeriveKey derives a key from a passphrase with Argon2id. Store the salt next
+// to the data: it is not secret, but without it the key cannot be derived
+// again.
+//
+// Salted Hashing:
+//
+// WithSaltedHash hashes every value with a fresh random salt, stored in
+// Result.HashSalt, and VerifySaltedHash checks a value against it. Salting
+// defeats precomputation but makes hashes of equal values differ, which rules
+// out equality joins on OriginalHash; keyed hashing keeps them deterministic.
 package pseudonymization
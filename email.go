@@ -0,0 +1,75 @@
+package pseudonymization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MetadataDomain is the Result.Metadata key holding the domain of a
+// pseudonymized email address
+const MetadataDomain = "domain"
+
+// PseudonymizeEmail pseudonymizes the local part of an email address while
+// keeping its domain visible in Result.Metadata[MetadataDomain], so users can
+// still be grouped by organization. The pseudonym stands in for the local
+// part only, while the full address is hashed and encrypted, so Revert returns
+// the complete original email.
+//
+// Parameters:
+// - email: The email address to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+//   - Result containing pseudonymization artifacts and the email domain
+//   - error wrapping ErrInvalidEmail if email does not contain exactly one @
+//     with a non-empty local part and domain
+func (s *Service) PseudonymizeEmail(email, purpose, system string) (_ *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if err = s.checkLength(len(email)); err != nil {
+		return nil, err
+	}
+	if email, err = s.normalizeInput(email); err != nil {
+		return nil, err
+	}
+	if len(email) == 0 {
+		return nil, ErrEmptyValue
+	}
+	if err := s.checkNotPseudonym(email); err != nil {
+		return nil, err
+	}
+
+	_, domain, err := splitEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.newResult(context.Background(), email, s.newPseudonym(), PseudonymizeOptions{Purpose: purpose, System: system})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Metadata = map[string]string{MetadataDomain: domain}
+	return result, nil
+}
+
+// splitEmail splits an email address into its local part and domain
+func splitEmail(email string) (local, domain string, err error) {
+	if strings.Count(email, "@") != 1 {
+		return "", "", fmt.Errorf("%w: must contain exactly one @", ErrInvalidEmail)
+	}
+
+	at := strings.IndexByte(email, '@')
+	local, domain = email[:at], email[at+1:]
+	if local == "" || domain == "" {
+		return "", "", fmt.Errorf("%w: empty local part or domain", ErrInvalidEmail)
+	}
+	if strings.ContainsAny(email, " \t\r\n") {
+		return "", "", fmt.Errorf("%w: contains whitespace", ErrInvalidEmail)
+	}
+
+	return local, domain, nil
+}
@@ -0,0 +1,38 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizeEmail(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.PseudonymizeEmail("maria.souza@empresa.com.br", "analytics", "crm")
+	assert.NoError(t, err)
+	assert.Equal(t, "empresa.com.br", result.Metadata[MetadataDomain])
+	assert.NotContains(t, result.Pseudonym, "maria")
+
+	original, err := svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "maria.souza@empresa.com.br", original)
+
+	invalid := []string{
+		"no-at-sign",
+		"two@@example.com",
+		"a@b@example.com",
+		"@example.com",
+		"user@",
+		"us er@example.com",
+	}
+	for _, email := range invalid {
+		t.Run(email, func(t *testing.T) {
+			_, err := svc.PseudonymizeEmail(email, "analytics", "crm")
+			assert.ErrorIs(t, err, ErrInvalidEmail)
+		})
+	}
+
+	_, err = svc.PseudonymizeEmail("", "analytics", "crm")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+}
@@ -0,0 +1,212 @@
+package pseudonymization
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// envelopeVersion is the first byte of a ciphertext in the envelope format
+const envelopeVersion = 1
+
+// envelopeHeaderSize is the size of the envelope prefix: format version and
+// algorithm ID
+const envelopeHeaderSize = 2
+
+// Algorithm IDs stored in the second byte of an envelope
+const (
+	algorithmAESGCM           byte = 1
+	algorithmAESSIV           byte = 2
+	algorithmChaCha20Poly1305 byte = 3
+)
+
+// algorithmID returns the envelope algorithm ID of the mode
+func (m EncryptionMode) algorithmID() byte {
+	switch m {
+	case ModeSIV:
+		return algorithmAESSIV
+	case ModeChaCha20Poly1305:
+		return algorithmChaCha20Poly1305
+	default:
+		return algorithmAESGCM
+	}
+}
+
+// modeFromAlgorithmID returns the mode of an envelope algorithm ID
+func modeFromAlgorithmID(id byte) (EncryptionMode, bool) {
+	switch id {
+	case algorithmAESGCM:
+		return ModeGCM, true
+	case algorithmAESSIV:
+		return ModeSIV, true
+	case algorithmChaCha20Poly1305:
+		return ModeChaCha20Poly1305, true
+	default:
+		return 0, false
+	}
+}
+
+// nonceSize returns the size of the nonce stored in ciphertexts of the mode
+func (m EncryptionMode) nonceSize() int {
+	if m == ModeSIV {
+		return 0 // the synthetic IV doubles as the tag
+	}
+	return 12
+}
+
+// envelopeHeader returns the envelope prefix for ciphertexts of mode
+func envelopeHeader(mode EncryptionMode) []byte {
+	return []byte{envelopeVersion, mode.algorithmID()}
+}
+
+// splitEnvelope returns the payload of data if it starts with the envelope
+// prefix of mode. Legacy ciphertexts start with a random nonce (or key
+// version), so a match is not proof of an envelope: callers fall back to
+// reading data as a legacy ciphertext when the payload fails to decrypt.
+func splitEnvelope(data []byte, mode EncryptionMode) ([]byte, bool) {
+	if len(data) < envelopeHeaderSize || data[0] != envelopeVersion || data[1] != mode.algorithmID() {
+		return nil, false
+	}
+	return data[envelopeHeaderSize:], true
+}
+
+// MigrateCiphertext upgrades an encrypted value from the legacy format, a
+// bare nonce || ciphertext (after the key version header of
+// NewServiceWithKeyring, if any), to the self-describing envelope format
+// written by services created with WithEnvelopeFormat:
+//
+//	format version (1) | algorithm ID (1) | legacy ciphertext
+//
+// The payload is kept as it is, so the value is neither decrypted nor
+// re-encrypted: values bound to a purpose and system, to additional data or
+// to an expiry migrate like any other, and the plaintext is never exposed.
+// As a consequence MigrateCiphertext cannot tell whether the value was
+// encrypted by this service; only its size is checked. Values already in the
+// envelope format are returned unchanged.
+//
+// Decrypting operations accept both formats, so values can be migrated in
+// place, in any order, while the service keeps running.
+//
+// Parameters:
+// - old: Base64-encoded encrypted value in the legacy format
+//
+// Returns:
+//   - Base64-encoded value in the envelope format, with the service's
+//     ciphertext encoding
+//   - error: ErrNotReversible for an empty value, ErrMalformedCiphertext for
+//     invalid base64, ErrCiphertextTooShort if the value is too short to be a
+//     ciphertext, or ErrServiceClosed
+func (s *Service) MigrateCiphertext(old string) (string, error) {
+	if old == "" {
+		return "", ErrNotReversible
+	}
+	data, err := decodeCiphertext(old)
+	if err != nil {
+		return "", err
+	}
+
+	migrated, err := s.ring.migrate(data)
+	if err != nil {
+		return "", err
+	}
+	if migrated == nil {
+		return old, nil
+	}
+	return s.encoding.EncodeToString(migrated), nil
+}
+
+// migrate prefixes the legacy ciphertext data with the envelope header, or
+// returns nil if data is already an envelope
+func (r *keyring) migrate(data []byte) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, ErrServiceClosed
+	}
+
+	// A legacy value that happens to start with the envelope prefix is left
+	// as it is; it still decrypts through the legacy fallback
+	if _, ok := splitEnvelope(data, r.mode); ok {
+		return nil, nil
+	}
+
+	aead := r.aeads[r.legacy]
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: %d bytes", ErrCiphertextTooShort, len(data))
+	}
+	return append(envelopeHeader(r.mode), data...), nil
+}
+
+// RevertInfo is the plaintext of a value together with how it was encrypted,
+// as returned by RevertDetailed
+type RevertInfo struct {
+	// Plaintext is the original value; empty when decryption failed
+	Plaintext string
+
+	// Enveloped reports whether the value is in the envelope format (see
+	// MigrateCiphertext) rather than the legacy format
+	Enveloped bool
+
+	// Algorithm is the cipher named by the envelope header or, for legacy
+	// values, the mode of the service
+	Algorithm EncryptionMode
+
+	// KeyVersion is the version of the key that decrypted the value or, on
+	// failure, the version named by its header; -1 if unknown. Services
+	// created without NewServiceWithKeyring have a single key, version 0.
+	KeyVersion int
+
+	// NonceSize is the size in bytes of the nonce stored in the value (0 for
+	// AES-SIV, whose synthetic IV is its tag)
+	NonceSize int
+}
+
+// RevertDetailed is Revert that also reports the key version and algorithm
+// the value was encrypted with, for debugging key rotations and format
+// migrations. When decryption fails, the returned RevertInfo still describes
+// the parsed header, e.g. an envelope naming a different algorithm than the
+// service's or a key version the service does not hold. Legacy values carry
+// no algorithm marker, so their Algorithm is the service's mode.
+//
+// Like Revert, a successful call is audited, and values bound to a purpose
+// and system, additional data or an expiry cannot be reverted this way.
+//
+// Parameters:
+// - encryptedValue: Base64-encoded encrypted value
+//
+// Returns:
+// - RevertInfo with the plaintext and header details
+// - error as for Revert
+func (s *Service) RevertDetailed(encryptedValue string) (info RevertInfo, err error) {
+	defer s.observeRevert(time.Now(), &err)
+
+	info = RevertInfo{Algorithm: s.ring.mode, KeyVersion: -1, NonceSize: s.ring.mode.nonceSize()}
+	if encryptedValue == "" {
+		return info, ErrNotReversible
+	}
+	if err := s.allowRevert(context.Background()); err != nil {
+		return info, err
+	}
+	data, err := decodeCiphertext(encryptedValue)
+	if err != nil {
+		return info, err
+	}
+
+	plaintext, header, err := s.ring.openDetailed(data, s.tenantAAD(s.additionalData("", "", nil)))
+	info.Enveloped = header.enveloped
+	info.Algorithm = header.mode
+	info.KeyVersion = header.version
+	info.NonceSize = header.mode.nonceSize()
+	if err != nil {
+		return info, err
+	}
+	defer wipe(plaintext)
+
+	info.Plaintext = string(plaintext)
+	hash, _ := s.originalHash(info.Plaintext)
+	s.audit(context.Background(), AuditEvent{
+		Operation:    OperationRevert,
+		OriginalHash: hash,
+	})
+	return info, nil
+}
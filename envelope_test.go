@@ -0,0 +1,200 @@
+package pseudonymization
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithEnvelopeFormat(t *testing.T) {
+	key := randomKey(t, 32)
+	svc := NewService(key, WithEnvelopeFormat())
+
+	encrypted, err := svc.Encrypt("52998224725")
+	assert.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{envelopeVersion, algorithmAESGCM}, data[:2])
+	assert.Len(t, data, 2+12+len("52998224725")+16)
+
+	// Every service reads both formats
+	for _, reader := range []*Service{svc, NewService(key)} {
+		original, err := reader.Decrypt(encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, "52998224725", original)
+	}
+	legacy, err := NewService(key).Encrypt("52998224725")
+	assert.NoError(t, err)
+	original, err := svc.Decrypt(legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// The key version header follows the envelope header
+	ring, err := NewServiceWithKeyring(map[int][]byte{3: key}, 3, WithEnvelopeFormat())
+	assert.NoError(t, err)
+	encrypted, err = ring.Encrypt("52998224725")
+	assert.NoError(t, err)
+	data, _ = base64.StdEncoding.DecodeString(encrypted)
+	assert.Equal(t, []byte{envelopeVersion, algorithmAESGCM, 3}, data[:3])
+	original, err = ring.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Other modes record their own algorithm ID
+	chacha, err := NewServiceWithMode(key, ModeChaCha20Poly1305, WithEnvelopeFormat())
+	assert.NoError(t, err)
+	encrypted, err = chacha.Encrypt("52998224725")
+	assert.NoError(t, err)
+	data, _ = base64.StdEncoding.DecodeString(encrypted)
+	assert.Equal(t, algorithmChaCha20Poly1305, data[1])
+
+	siv, err := NewServiceWithMode(randomKey(t, 64), ModeSIV, WithEnvelopeFormat())
+	assert.NoError(t, err)
+	encrypted, err = siv.Encrypt("52998224725")
+	assert.NoError(t, err)
+	data, _ = base64.StdEncoding.DecodeString(encrypted)
+	assert.Equal(t, algorithmAESSIV, data[1])
+	original, err = siv.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+}
+
+func TestEnvelopeLegacyLookalike(t *testing.T) {
+	key := randomKey(t, 32)
+	aead, err := newAEAD(key)
+	assert.NoError(t, err)
+
+	// A legacy ciphertext whose random nonce starts like an envelope
+	nonce := []byte{envelopeVersion, algorithmAESGCM, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	legacy := base64.StdEncoding.EncodeToString(aead.Seal(append([]byte(nil), nonce...), nonce, []byte("52998224725"), nil))
+
+	original, err := NewService(key).Decrypt(legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Tampered envelopes still fail
+	svc := NewService(key, WithEnvelopeFormat())
+	encrypted, err := svc.Encrypt("52998224725")
+	assert.NoError(t, err)
+	data, _ := base64.StdEncoding.DecodeString(encrypted)
+	data[len(data)-1] ^= 1
+	_, err = svc.Decrypt(base64.StdEncoding.EncodeToString(data))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestMigrateCiphertext(t *testing.T) {
+	key := randomKey(t, 32)
+	legacySvc := NewService(key, WithPurposeBinding())
+	svc := NewService(key, WithPurposeBinding())
+
+	// Bound values with an expiry migrate without their purpose or expiry
+	result, err := legacySvc.PseudonymizeWithTTL("52998224725", "billing", "erp", time.Hour)
+	assert.NoError(t, err)
+
+	migrated, err := svc.MigrateCiphertext(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.NotEqual(t, result.EncryptedValue, migrated)
+	data, err := base64.StdEncoding.DecodeString(migrated)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{envelopeVersion, algorithmAESGCM}, data[:2])
+
+	result.EncryptedValue = migrated
+	original, err := svc.RevertResult(result, RevertOptions{Purpose: "billing", System: "erp"})
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Migration is idempotent
+	again, err := svc.MigrateCiphertext(migrated)
+	assert.NoError(t, err)
+	assert.Equal(t, migrated, again)
+
+	// Enveloped values still work with ReEncrypt and RevertAny
+	plain, err := NewService(key).Encrypt("52998224725")
+	assert.NoError(t, err)
+	enveloped, err := svc.MigrateCiphertext(plain)
+	assert.NoError(t, err)
+	newKey := randomKey(t, 32)
+	reencrypted, err := NewService(newKey).ReEncrypt(enveloped, key)
+	assert.NoError(t, err)
+	original, keyIndex, err := svc.RevertAny(enveloped, [][]byte{newKey, key})
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+	assert.Equal(t, 1, keyIndex)
+	original, err = NewService(newKey).Decrypt(reencrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	_, err = svc.MigrateCiphertext("")
+	assert.ErrorIs(t, err, ErrNotReversible)
+	_, err = svc.MigrateCiphertext("not base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+	_, err = svc.MigrateCiphertext("AAAA")
+	assert.ErrorIs(t, err, ErrCiphertextTooShort)
+
+	svc.Close()
+	_, err = svc.MigrateCiphertext(plain)
+	assert.ErrorIs(t, err, ErrServiceClosed)
+}
+
+func TestRevertDetailed(t *testing.T) {
+	keys := map[int][]byte{1: randomKey(t, 32), 2: randomKey(t, 32)}
+	logger := &recordingAuditLogger{}
+	svc, err := NewServiceWithKeyring(keys, 2, WithEnvelopeFormat(), WithAuditLogger(logger))
+	assert.NoError(t, err)
+
+	encrypted, err := svc.Encrypt("52998224725")
+	assert.NoError(t, err)
+	info, err := svc.RevertDetailed(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, RevertInfo{Plaintext: "52998224725", Enveloped: true, Algorithm: ModeGCM, KeyVersion: 2, NonceSize: 12}, info)
+	assert.Len(t, logger.events, 1)
+
+	// Legacy values decrypted with an older key
+	old, err := NewServiceWithKeyring(keys, 1)
+	assert.NoError(t, err)
+	encrypted, err = old.Encrypt("52998224725")
+	assert.NoError(t, err)
+	info, err = svc.RevertDetailed(encrypted)
+	assert.NoError(t, err)
+	assert.False(t, info.Enveloped)
+	assert.Equal(t, 1, info.KeyVersion)
+
+	// Unversioned services have a single key, version 0
+	plain := NewService(keys[1])
+	encrypted, err = plain.Encrypt("52998224725")
+	assert.NoError(t, err)
+	info, err = plain.RevertDetailed(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, info.KeyVersion)
+
+	// On failure the parsed header is still reported
+	siv, err := NewServiceWithMode(randomKey(t, 64), ModeSIV, WithEnvelopeFormat())
+	assert.NoError(t, err)
+	encrypted, err = siv.Encrypt("52998224725")
+	assert.NoError(t, err)
+	info, err = svc.RevertDetailed(encrypted)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	assert.Contains(t, err.Error(), "AES-SIV")
+	assert.Empty(t, info.Plaintext)
+	assert.True(t, info.Enveloped)
+	assert.Equal(t, ModeSIV, info.Algorithm)
+	assert.Equal(t, 0, info.NonceSize)
+
+	other, err := NewServiceWithKeyring(map[int][]byte{2: randomKey(t, 32)}, 2, WithEnvelopeFormat())
+	assert.NoError(t, err)
+	encrypted, err = other.Encrypt("52998224725")
+	assert.NoError(t, err)
+	info, err = svc.RevertDetailed(encrypted)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	assert.True(t, info.Enveloped)
+	assert.Equal(t, ModeGCM, info.Algorithm)
+	assert.Equal(t, 2, info.KeyVersion)
+
+	_, err = svc.RevertDetailed("")
+	assert.ErrorIs(t, err, ErrNotReversible)
+	_, err = svc.RevertDetailed("not base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+	assert.Len(t, logger.events, 2)
+}
@@ -0,0 +1,147 @@
+package pseudonymization
+
+import (
+	"errors"
+
+	"github.com/raywall/pseudonymization-lgpd-tools/utils"
+)
+
+// Sentinel errors returned (possibly wrapped) by Service methods. Use
+// errors.Is to classify a failure.
+var (
+	// ErrServiceClosed is returned by operations on a Service after Close
+	ErrServiceClosed = errors.New("service is closed")
+
+	// ErrEmptyValue is returned when the value to pseudonymize is empty
+	ErrEmptyValue = errors.New("value cannot be empty")
+
+	// ErrInvalidCPF is returned when a value is not a valid CPF (same value as
+	// utils.ErrInvalidCPF)
+	ErrInvalidCPF = utils.ErrInvalidCPF
+
+	// ErrInvalidEmail is returned when a value is not a well-formed email address
+	ErrInvalidEmail = errors.New("invalid email address")
+
+	// ErrInvalidPhone is returned when a value is not a valid Brazilian phone
+	// number (same value as utils.ErrInvalidPhone)
+	ErrInvalidPhone = utils.ErrInvalidPhone
+
+	// ErrValueTooLarge is returned when a value exceeds the service's maximum
+	// length (see WithMaxValueLength)
+	ErrValueTooLarge = errors.New("value too large")
+
+	// ErrAlreadyPseudonymized is returned in strict mode (see WithStrictMode)
+	// when the value to pseudonymize is already a UUID pseudonym
+	ErrAlreadyPseudonymized = errors.New("value is already a pseudonym")
+
+	// ErrInvalidUTF8 is returned when a string value is not valid UTF-8;
+	// use EncryptBytes for binary data
+	ErrInvalidUTF8 = errors.New("value is not valid UTF-8")
+
+	// ErrInvalidPseudonym is returned when a caller-supplied pseudonym is
+	// empty or malformed
+	ErrInvalidPseudonym = errors.New("invalid pseudonym")
+
+	// ErrInvalidStruct is returned by PseudonymizeStruct for a value that is
+	// not a non-nil struct pointer, or a tagged field that is not a string
+	ErrInvalidStruct = errors.New("invalid struct")
+
+	// ErrInvalidJSONPath is returned by PseudonymizeJSON for a malformed path
+	// or a path that selects a value other than a string
+	ErrInvalidJSONPath = errors.New("invalid JSON path")
+
+	// ErrPseudonymMismatch is returned by RevertJSON when a document does not
+	// hold the pseudonym its Result expects at a path
+	ErrPseudonymMismatch = errors.New("pseudonym mismatch")
+
+	// ErrRateLimited is returned by the revert methods when the service's
+	// RateLimiter denies a decryption attempt (see WithRateLimiter)
+	ErrRateLimited = errors.New("revert rate limit exceeded")
+
+	// ErrInvalidResult is returned when a serialized Result is malformed
+	ErrInvalidResult = errors.New("invalid result")
+
+	// ErrInvalidKeyLength is returned when the encryption key is not a valid
+	// size for the encryption mode (16, 24 or 32 bytes for AES-GCM, 64 bytes
+	// for AES-SIV, 32 bytes for ChaCha20-Poly1305)
+	ErrInvalidKeyLength = errors.New("invalid encryption key length")
+
+	// ErrWeakKey is returned when the encryption key is all zeros or repeats
+	// a short byte pattern, usually a key buffer that was never filled
+	ErrWeakKey = errors.New("weak encryption key")
+
+	// ErrInvalidHMACKey is returned when the configured HMAC key is too short
+	ErrInvalidHMACKey = errors.New("HMAC key must be at least 16 bytes")
+
+	// ErrNoHMACKey is returned by Anonymize when the service was created
+	// without WithHMACKey
+	ErrNoHMACKey = errors.New("no HMAC key configured")
+
+	// ErrNotReversible is returned by Revert for an empty encrypted value, as
+	// found in a Result produced by Anonymize
+	ErrNotReversible = errors.New("value is anonymized and cannot be reverted")
+
+	// ErrExpired is returned when reverting a value whose retention period
+	// (Result.ExpiresAt) has passed
+	ErrExpired = errors.New("pseudonymized value has expired")
+
+	// ErrInvalidTTL is returned for a negative or, in PseudonymizeWithTTL,
+	// zero TTL
+	ErrInvalidTTL = errors.New("invalid TTL")
+
+	// ErrSaltTooShort is returned by DeriveKey when the salt is shorter than
+	// MinSaltLength
+	ErrSaltTooShort = errors.New("salt too short")
+
+	// ErrInvalidKDFParams is returned by DeriveKeyWithParams for unusable
+	// Argon2id parameters
+	ErrInvalidKDFParams = errors.New("invalid key derivation parameters")
+
+	// ErrUnsupportedMode is returned for an unknown EncryptionMode
+	ErrUnsupportedMode = errors.New("unsupported encryption mode")
+
+	// ErrUnsupportedHashAlgorithm is returned for an unknown HashAlgorithm
+	ErrUnsupportedHashAlgorithm = errors.New("unsupported hash algorithm")
+
+	// ErrUnsupportedHashEncoding is returned for an unknown HashEncoding
+	ErrUnsupportedHashEncoding = errors.New("unsupported hash encoding")
+
+	// ErrUnknownKeyVersion is returned when a keyring refers to a key version
+	// it does not hold
+	ErrUnknownKeyVersion = errors.New("unknown key version")
+
+	// ErrNoTokenVault is returned by Tokenize and Detokenize when the service
+	// was created without WithTokenVault
+	ErrNoTokenVault = errors.New("no token vault configured")
+
+	// ErrTokenNotFound is returned when a token is not present in the vault
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrPseudonymCollision is returned when a deterministic pseudonym was
+	// already derived from a different value (see WithCollisionChecker)
+	ErrPseudonymCollision = errors.New("deterministic pseudonym collision")
+
+	// ErrNonceLimitApproaching is returned by encrypting operations once the
+	// service has used its active key for the configured number of
+	// encryptions (see WithNonceLimit); the key must be rotated
+	ErrNonceLimitApproaching = errors.New("nonce limit reached, rotate the encryption key")
+
+	// ErrRoundTripFailed is returned by VerifyRoundTrip when a value does not
+	// survive encryption and decryption unchanged
+	ErrRoundTripFailed = errors.New("round trip verification failed")
+
+	// ErrMalformedCiphertext is returned when an encrypted value is not valid base64
+	ErrMalformedCiphertext = errors.New("malformed ciphertext")
+
+	// ErrCiphertextTooShort is returned when an encrypted value is too short
+	// to contain a nonce and authentication tag
+	ErrCiphertextTooShort = errors.New("ciphertext too short")
+
+	// ErrTruncatedStream is returned when an encrypted stream ends before its
+	// final chunk
+	ErrTruncatedStream = errors.New("encrypted stream is truncated")
+
+	// ErrDecryptionFailed is returned when a ciphertext fails authentication,
+	// e.g. because it was tampered with or encrypted under a different key
+	ErrDecryptionFailed = errors.New("decryption failed")
+)
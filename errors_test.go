@@ -0,0 +1,40 @@
+package pseudonymization
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClassification(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	_, err := svc.Pseudonymize("", "test", "test")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+
+	_, err = NewServiceWithError(make([]byte, 10))
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+
+	_, err = svc.Revert("not base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+
+	_, err = svc.Revert(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorIs(t, err, ErrCiphertextTooShort)
+
+	// Encrypted under a different key
+	result, err := NewService(randomKey(t, 32)).Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	assert.NotErrorIs(t, err, ErrMalformedCiphertext)
+
+	// Tampered ciphertext
+	result, err = svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(result.EncryptedValue)
+	assert.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	_, err = svc.Revert(base64.StdEncoding.EncodeToString(data))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
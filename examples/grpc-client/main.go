@@ -0,0 +1,54 @@
+// Command grpc-client calls a pseudonymization gRPC server (see
+// transport/grpc) to pseudonymize a value and revert it again.
+//
+// Usage:
+//
+//	grpc-client -addr localhost:50051 -value 52998224725
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/raywall/pseudonymization-lgpd-tools/transport/grpc/pseudonymizationpb"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "address of the pseudonymization server")
+	value := flag.String("value", "52998224725", "value to pseudonymize")
+	flag.Parse()
+
+	// Use TLS credentials in production: the server can re-identify data
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal("Failed to connect:", err)
+	}
+	defer conn.Close()
+	client := pseudonymizationpb.NewPseudonymizationClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := client.Pseudonymize(ctx, &pseudonymizationpb.PseudonymizeRequest{
+		Value:   *value,
+		Purpose: "data-processing",
+		System:  "grpc-client-example",
+	})
+	if err != nil {
+		log.Fatal("Pseudonymization failed:", err)
+	}
+	fmt.Printf("Pseudonym: %s\n", result.GetPseudonym())
+	fmt.Printf("Original hash: %s\n", result.GetOriginalHash())
+
+	reverted, err := client.Revert(ctx, &pseudonymizationpb.RevertRequest{EncryptedValue: result.GetEncryptedValue()})
+	if err != nil {
+		log.Fatal("Revert failed:", err)
+	}
+	fmt.Printf("Original value: %s\n", reverted.GetValue())
+}
@@ -0,0 +1,100 @@
+package pseudonymization
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PseudonymGenerator creates the random pseudonyms of a Service (see
+// WithPseudonymGenerator). Generate must return a new unique value on every
+// call and be safe for concurrent use. Deterministic pseudonyms are always
+// UUID v5 and do not use the generator.
+type PseudonymGenerator interface {
+	Generate() string
+}
+
+// UUIDv4Generator generates random UUID v4 pseudonyms. This is the default:
+// they reveal nothing, not even when the record was created, but are not
+// sortable, so as primary keys they scatter inserts across the index.
+type UUIDv4Generator struct{}
+
+// Generate returns a new UUID v4
+func (UUIDv4Generator) Generate() string {
+	return uuid.New().String()
+}
+
+// UUIDv7Generator generates time-ordered UUID v7 pseudonyms (RFC 9562): a
+// millisecond timestamp followed by random bits. They sort by creation time,
+// which keeps B-tree inserts local, but anyone holding a pseudonym can read
+// when it was created; in a pseudonymized dataset that may help link records
+// to people.
+type UUIDv7Generator struct{}
+
+// Generate returns a new UUID v7
+func (UUIDv7Generator) Generate() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// ULIDGenerator generates ULID pseudonyms: 26 Crockford base32 characters
+// encoding a millisecond timestamp and 80 random bits. Like UUID v7 they sort
+// by creation time and leak it to anyone holding a pseudonym.
+type ULIDGenerator struct{}
+
+// crockfordAlphabet is the base32 alphabet of ULIDs, without I, L, O and U
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidLength is the number of characters in a ULID
+const ulidLength = 26
+
+// Generate returns a new ULID
+func (ULIDGenerator) Generate() string {
+	var raw [16]byte
+	binary.BigEndian.PutUint64(raw[:8], uint64(time.Now().UnixMilli())<<16)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(raw[6:])
+	return encodeULID(raw)
+}
+
+// encodeULID encodes the 128 bits of raw as 26 base32 characters, the first
+// of which carries only 3 bits
+func encodeULID(raw [16]byte) string {
+	hi := binary.BigEndian.Uint64(raw[:8])
+	lo := binary.BigEndian.Uint64(raw[8:])
+	var out [ulidLength]byte
+	for i := ulidLength - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// isULID reports whether value has the shape of a ULID
+func isULID(value string) bool {
+	if len(value) != ulidLength || value[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if strings.IndexByte(crockfordAlphabet, c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// newPseudonym returns a random pseudonym from the configured generator, or
+// a UUID v4 drawn from the service's random source
+func (s *Service) newPseudonym() string {
+	if s.generator == nil {
+		return uuid.Must(uuid.NewRandomFromReader(s.ring.random)).String()
+	}
+	return s.generator.Generate()
+}
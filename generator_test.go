@@ -0,0 +1,74 @@
+package pseudonymization
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymGenerators(t *testing.T) {
+	id, err := uuid.Parse(UUIDv4Generator{}.Generate())
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Version(4), id.Version())
+
+	id, err = uuid.Parse(UUIDv7Generator{}.Generate())
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+
+	ulid := ULIDGenerator{}.Generate()
+	assert.Len(t, ulid, ulidLength)
+	assert.True(t, isULID(ulid))
+	assert.NotEqual(t, ulid, ULIDGenerator{}.Generate())
+
+	// Time-ordered generators sort by creation time
+	for _, generator := range []PseudonymGenerator{UUIDv7Generator{}, ULIDGenerator{}} {
+		first := generator.Generate()
+		time.Sleep(2 * time.Millisecond)
+		second := generator.Generate()
+		assert.Less(t, first, second)
+	}
+}
+
+func TestEncodeULID(t *testing.T) {
+	// Smallest and largest ULIDs of the specification
+	assert.Equal(t, "00000000000000000000000000", encodeULID([16]byte{}))
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID([16]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	}))
+	assert.Equal(t, "0000000000000000000000000Z", encodeULID([16]byte{15: 31}))
+
+	assert.True(t, isULID("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	assert.True(t, isULID("01arz3ndektsv4rrffq69g5fav"))
+	assert.False(t, isULID("81ARZ3NDEKTSV4RRFFQ69G5FAV")) // Overflows 128 bits
+	assert.False(t, isULID("01ARZ3NDEKTSV4RRFFQ69G5FAU")) // U is not in the alphabet
+	assert.False(t, isULID("01ARZ3NDEKTSV4RRFFQ69G5FA"))  // Too short
+}
+
+func TestWithPseudonymGenerator(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithPseudonymGenerator(ULIDGenerator{}), WithStrictMode())
+
+	var pseudonyms []string
+	for range 3 {
+		result, err := svc.Pseudonymize("52998224725", "test", "test")
+		assert.NoError(t, err)
+		assert.True(t, isULID(result.Pseudonym))
+		assert.NoError(t, result.Validate())
+		pseudonyms = append(pseudonyms, result.Pseudonym)
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, slices.IsSorted(pseudonyms))
+
+	// Strict mode recognizes the generator's own pseudonyms
+	_, err := svc.Pseudonymize(pseudonyms[0], "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+
+	// Deterministic pseudonyms stay UUID v5
+	result, err := svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.NoError(t, err)
+	id, err := uuid.Parse(result.Pseudonym)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Version(5), id.Version())
+}
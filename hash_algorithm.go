@@ -0,0 +1,93 @@
+package pseudonymization
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// HashAlgorithm selects the hash function a Service uses for
+// Result.OriginalHash, Hash, HashKeyed, the salted hashes and their Verify
+// counterparts. With an HMAC key the same function is used inside HMAC.
+//
+// The length of OriginalHash depends on the algorithm: 64 hex characters for
+// HashSHA256 and HashSHA3_256, 128 for HashSHA512 and HashBLAKE2b. Hashes are
+// only comparable between services using the same algorithm.
+type HashAlgorithm int
+
+const (
+	// HashSHA256 hashes with SHA-256. This is the default.
+	HashSHA256 HashAlgorithm = iota
+
+	// HashSHA512 hashes with SHA-512
+	HashSHA512
+
+	// HashSHA3_256 hashes with SHA3-256 (FIPS 202)
+	HashSHA3_256
+
+	// HashBLAKE2b hashes with BLAKE2b-512 (RFC 7693)
+	HashBLAKE2b
+)
+
+// String returns the name of the algorithm
+func (a HashAlgorithm) String() string {
+	switch a {
+	case HashSHA256:
+		return "SHA-256"
+	case HashSHA512:
+		return "SHA-512"
+	case HashSHA3_256:
+		return "SHA3-256"
+	case HashBLAKE2b:
+		return "BLAKE2b-512"
+	default:
+		return fmt.Sprintf("HashAlgorithm(%d)", int(a))
+	}
+}
+
+// Size returns the digest size of the algorithm in bytes, or 0 for an unknown
+// algorithm
+func (a HashAlgorithm) Size() int {
+	switch a {
+	case HashSHA256, HashSHA3_256:
+		return sha256.Size
+	case HashSHA512:
+		return sha512.Size
+	case HashBLAKE2b:
+		return blake2b.Size
+	default:
+		return 0
+	}
+}
+
+// newHash returns the constructor of the algorithm, or nil for an unknown
+// algorithm
+func (a HashAlgorithm) newHash() func() hash.Hash {
+	switch a {
+	case HashSHA256:
+		return sha256.New
+	case HashSHA512:
+		return sha512.New
+	case HashSHA3_256:
+		return func() hash.Hash { return sha3.New256() }
+	case HashBLAKE2b:
+		return func() hash.Hash {
+			// Only fails for keys longer than 64 bytes
+			h, _ := blake2b.New512(nil)
+			return h
+		}
+	default:
+		return nil
+	}
+}
+
+// sum returns the unkeyed digest of data
+func (a HashAlgorithm) sum(data []byte) []byte {
+	h := a.newHash()()
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,66 @@
+package pseudonymization
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashAlgorithm(t *testing.T) {
+	// Known answers for "abc"
+	testCases := []struct {
+		algorithm HashAlgorithm
+		name      string
+		prefix    string
+	}{
+		{HashSHA256, "SHA-256", "ba7816bf8f01cfea"},
+		{HashSHA512, "SHA-512", "ddaf35a193617aba"},
+		{HashSHA3_256, "SHA3-256", "3a985da74fe225b2"},
+		{HashBLAKE2b, "BLAKE2b-512", "ba80a53f981c4d0d"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := NewServiceWithError(randomKey(t, 32), WithHashAlgorithm(tc.algorithm))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.name, tc.algorithm.String())
+
+			hash := svc.Hash("abc")
+			assert.Len(t, hash, 2*tc.algorithm.Size())
+			assert.Equal(t, tc.prefix, hash[:16])
+
+			result, err := svc.Pseudonymize("abc", "test", "test")
+			assert.NoError(t, err)
+			assert.Equal(t, hash, result.OriginalHash)
+			assert.NoError(t, result.Validate())
+			assert.True(t, svc.VerifyHash("abc", result.OriginalHash))
+			assert.False(t, svc.VerifyHash("abd", result.OriginalHash))
+
+			salted, salt := svc.HashWithSalt("abc")
+			assert.Len(t, salted, 2*tc.algorithm.Size())
+			assert.True(t, svc.VerifySaltedHash("abc", salted, salt))
+		})
+	}
+
+	assert.Equal(t, "HashAlgorithm(9)", HashAlgorithm(9).String())
+	_, err := NewServiceWithError(randomKey(t, 32), WithHashAlgorithm(HashAlgorithm(9)))
+	assert.ErrorIs(t, err, ErrUnsupportedHashAlgorithm)
+}
+
+func TestHashAlgorithmKeyed(t *testing.T) {
+	hmacKey := randomKey(t, 32)
+	svc, err := NewServiceWithError(randomKey(t, 32), WithHMACKey(hmacKey), WithHashAlgorithm(HashSHA512))
+	assert.NoError(t, err)
+
+	mac := hmac.New(sha512.New, hmacKey)
+	mac.Write([]byte("abc"))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), svc.HashKeyed("abc"))
+
+	// Hashes are not comparable across algorithms
+	other, err := NewServiceWithError(randomKey(t, 32), WithHMACKey(hmacKey))
+	assert.NoError(t, err)
+	assert.False(t, other.VerifyHash("abc", svc.HashKeyed("abc")))
+}
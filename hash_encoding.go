@@ -0,0 +1,89 @@
+package pseudonymization
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// HashEncoding selects how a Service writes the digests it returns as
+// strings: Result.OriginalHash, Hash, HashKeyed, HashWithSalt and Rehash.
+// VerifyHash and VerifySaltedHash expect hashes in the same encoding. Salts
+// (Result.HashSalt) are always hex encoded.
+//
+// A SHA-256 digest takes 64 characters in hex, 43 in base64 and 52 in
+// base32. The encoding only changes the representation, not the digest, so
+// hashes stored under one encoding can be converted to another, but compare
+// unequal as strings until they are.
+type HashEncoding int
+
+const (
+	// HashEncodingHex writes lowercase hexadecimal. This is the default.
+	HashEncodingHex HashEncoding = iota
+
+	// HashEncodingBase64 writes unpadded, URL-safe base64 (RFC 4648,
+	// section 5), the shortest of the three
+	HashEncodingBase64
+
+	// HashEncodingBase32 writes unpadded, uppercase base32 (RFC 4648,
+	// section 6), which survives case-insensitive storage; it is decoded
+	// regardless of case
+	HashEncodingBase32
+)
+
+// String returns the name of the encoding
+func (e HashEncoding) String() string {
+	switch e {
+	case HashEncodingHex:
+		return "hex"
+	case HashEncodingBase64:
+		return "base64"
+	case HashEncodingBase32:
+		return "base32"
+	default:
+		return fmt.Sprintf("HashEncoding(%d)", int(e))
+	}
+}
+
+// valid reports whether e is a known encoding
+func (e HashEncoding) valid() bool {
+	return e >= HashEncodingHex && e <= HashEncodingBase32
+}
+
+// encode returns digest in the encoding
+func (e HashEncoding) encode(digest []byte) string {
+	switch e {
+	case HashEncodingBase64:
+		return base64.RawURLEncoding.EncodeToString(digest)
+	case HashEncodingBase32:
+		return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(digest)
+	default:
+		return hex.EncodeToString(digest)
+	}
+}
+
+// decode parses a digest written in the encoding
+func (e HashEncoding) decode(encoded string) ([]byte, error) {
+	switch e {
+	case HashEncodingBase64:
+		return base64.RawURLEncoding.DecodeString(encoded)
+	case HashEncodingBase32:
+		return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(encoded))
+	default:
+		return hex.DecodeString(encoded)
+	}
+}
+
+// validHash reports whether hash is a digest of a supported size in any of
+// the encodings. The sizes keep the encodings apart: a 32 or 64-byte digest
+// never has the same length in two of them.
+func validHash(hash string) bool {
+	for _, encoding := range []HashEncoding{HashEncodingHex, HashEncodingBase64, HashEncodingBase32} {
+		if digest, err := encoding.decode(hash); err == nil && (len(digest) == sha256Size || len(digest) == sha512Size) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package pseudonymization
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashEncoding(t *testing.T) {
+	// SHA-256 of "abc" in each encoding
+	testCases := []struct {
+		encoding HashEncoding
+		name     string
+		hash     string
+	}{
+		{HashEncodingHex, "hex", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{HashEncodingBase64, "base64", "ungWv48Bz-pBQUDeXa4iI7ADYaOWF3qctBD_YfIAFa0"},
+		{HashEncodingBase32, "base32", "XJ4BNP4PAHH6UQKBIDPF3LRCEOYAGYNDSYLXVHFUCD7WD4QACWWQ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := NewServiceWithError(randomKey(t, 32), WithHashEncoding(tc.encoding))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.name, tc.encoding.String())
+			assert.Equal(t, tc.hash, svc.Hash("abc"))
+
+			result, err := svc.Pseudonymize("abc", "test", "test")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.hash, result.OriginalHash)
+			assert.NoError(t, result.Validate())
+			assert.True(t, svc.VerifyHash("abc", result.OriginalHash))
+			assert.False(t, svc.VerifyHash("abd", result.OriginalHash))
+
+			rehashed, err := svc.Rehash(result.EncryptedValue)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.hash, rehashed)
+
+			salted, salt := svc.HashWithSalt("abc")
+			assert.True(t, svc.VerifySaltedHash("abc", salted, salt))
+		})
+	}
+
+	// Base32 hashes survive case-insensitive storage
+	svc := NewService(randomKey(t, 32), WithHashEncoding(HashEncodingBase32))
+	assert.True(t, svc.VerifyHash("abc", strings.ToLower(testCases[2].hash)))
+
+	// Hashes only verify in the encoding of the service
+	assert.False(t, svc.VerifyHash("abc", testCases[0].hash))
+	assert.False(t, NewService(randomKey(t, 32)).VerifyHash("abc", testCases[1].hash))
+
+	assert.Equal(t, "HashEncoding(7)", HashEncoding(7).String())
+	_, err := NewServiceWithError(randomKey(t, 32), WithHashEncoding(HashEncoding(7)))
+	assert.ErrorIs(t, err, ErrUnsupportedHashEncoding)
+}
+
+func TestHashEncodingKeyed(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)),
+		WithHashAlgorithm(HashSHA512), WithHashEncoding(HashEncodingBase64))
+
+	hash := svc.HashKeyed("52998224725")
+	assert.Len(t, hash, 86)
+	assert.True(t, svc.VerifyHash("52998224725", hash))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, hash, result.OriginalHash)
+	assert.NoError(t, result.Validate())
+}
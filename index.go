@@ -0,0 +1,63 @@
+package pseudonymization
+
+import "sync"
+
+// Index is an in-memory lookup of Results by the original value, keyed by
+// Result.OriginalHash. It hashes lookups with the Service that created it,
+// so the normalizer, HMAC key and hash algorithm always match those used
+// to pseudonymize. An Index is safe for concurrent use.
+//
+// Results with a salted hash (see WithSaltedHash) cannot be found by value,
+// since their hash depends on a per-value salt; Add ignores them.
+type Index struct {
+	svc     *Service
+	mu      sync.RWMutex
+	results map[string]*Result
+}
+
+// NewIndex creates an empty Index whose lookups are hashed by s
+func (s *Service) NewIndex() *Index {
+	return &Index{svc: s, results: make(map[string]*Result)}
+}
+
+// Add stores result under its OriginalHash, replacing any Result for the
+// same value. Nil Results, Results without a hash and salted Results are
+// ignored.
+//
+// Parameters:
+// - result: Result returned by the index's Service
+func (x *Index) Add(result *Result) {
+	if result == nil || result.OriginalHash == "" || result.HashSalt != "" {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.results[result.OriginalHash] = result
+}
+
+// Lookup hashes value as the index's Service does for Result.OriginalHash and
+// returns the Result stored for it
+//
+// Parameters:
+// - value: The original value to look up
+//
+// Returns:
+//   - Result stored for value
+//   - bool: false if no Result was added for value or the Service is closed
+func (x *Index) Lookup(value string) (*Result, bool) {
+	hash, err := x.svc.originalHash(x.svc.normalize(value))
+	if err != nil {
+		return nil, false
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	result, ok := x.results[hash]
+	return result, ok
+}
+
+// Len returns the number of Results in the index
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.results)
+}
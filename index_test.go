@@ -0,0 +1,52 @@
+package pseudonymization
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex(t *testing.T) {
+	svc := NewService(randomKey(t, 32),
+		WithHMACKey(randomKey(t, 32)),
+		WithNormalizer(strings.ToLower),
+		WithHashAlgorithm(HashSHA512))
+	index := svc.NewIndex()
+
+	alice, err := svc.Pseudonymize("alice@example.com", "crm", "web")
+	assert.NoError(t, err)
+	bob, err := svc.Pseudonymize("bob@example.com", "crm", "web")
+	assert.NoError(t, err)
+	index.Add(alice)
+	index.Add(bob)
+	index.Add(nil)
+	assert.Equal(t, 2, index.Len())
+
+	// Lookups are normalized and hashed like Pseudonymize
+	found, ok := index.Lookup("Alice@Example.com")
+	assert.True(t, ok)
+	assert.Same(t, alice, found)
+	found, ok = index.Lookup("bob@example.com")
+	assert.True(t, ok)
+	assert.Same(t, bob, found)
+
+	_, ok = index.Lookup("carol@example.com")
+	assert.False(t, ok)
+
+	// Another service hashes differently
+	_, ok = NewService(randomKey(t, 32)).NewIndex().Lookup("alice@example.com")
+	assert.False(t, ok)
+
+	// Salted hashes cannot be looked up
+	salted := NewService(randomKey(t, 32), WithSaltedHash())
+	result, err := salted.Pseudonymize("alice@example.com", "crm", "web")
+	assert.NoError(t, err)
+	saltedIndex := salted.NewIndex()
+	saltedIndex.Add(result)
+	assert.Equal(t, 0, saltedIndex.Len())
+
+	svc.Close()
+	_, ok = index.Lookup("alice@example.com")
+	assert.False(t, ok)
+}
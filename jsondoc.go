@@ -0,0 +1,264 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is one step of a parsed JSON path: an object key, an array
+// index, or every element of an array
+type jsonPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// jsonField is a string found at a JSON path by PseudonymizeJSON or RevertJSON
+type jsonField struct {
+	path  string
+	value string
+	set   func(string)
+}
+
+// PseudonymizeJSON replaces the strings found at the given paths of a JSON
+// document with their pseudonyms. Paths use a small JSONPath subset:
+// "$" followed by object keys (".customer.cpf"), array indexes ("[0]") and
+// array wildcards ("[*]"), e.g. "$.orders[*].buyer.email". Paths that match
+// nothing, null values and empty strings are skipped.
+//
+// Either every matched value is replaced or, on error, the document is not
+// returned at all. The output is re-encoded: object keys come out sorted and
+// insignificant whitespace is dropped, while numbers keep their exact text.
+//
+// Parameters:
+// - doc: The JSON document
+// - paths: Paths of the string values to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+//   - The document with pseudonyms in place of the matched values
+//   - Results by concrete path, with wildcards resolved (e.g.
+//     "$.orders[1].buyer.email"), for Revert
+//   - error wrapping ErrInvalidJSONPath for a malformed path or a matched
+//     value that is not a string, an error for malformed JSON, or the error
+//     of the first value that failed to pseudonymize
+func (s *Service) PseudonymizeJSON(doc []byte, paths []string, purpose, system string) (out []byte, results map[string]*Result, err error) {
+	root, fields, err := collectJSONPaths(doc, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Pseudonymize everything before touching the document, so a failure
+	// leaves nothing half done
+	results = make(map[string]*Result, len(fields))
+	for _, field := range fields {
+		result, err := s.Pseudonymize(field.value, purpose, system)
+		if err != nil {
+			return nil, nil, fmt.Errorf("path %s: %w", field.path, err)
+		}
+		results[field.path] = result
+	}
+	for _, field := range fields {
+		field.set(results[field.path].Pseudonym)
+	}
+
+	if out, err = encodeJSONDocument(root); err != nil {
+		return nil, nil, err
+	}
+	return out, results, nil
+}
+
+// RevertJSON is the counterpart of PseudonymizeJSON: it restores the original
+// values at the given paths of a pseudonymized document, reverting each with
+// the Result PseudonymizeJSON returned for its concrete path. Before anything
+// is restored, every matched value must equal the pseudonym of its Result, so
+// a document that was edited or reordered since (an array element removed,
+// say) fails instead of restoring a value into the wrong field.
+//
+// Every revert is audited, as with Revert; values bound to a purpose and
+// system with WithPurposeBinding cannot be reverted this way. Either every
+// matched value is restored or, on error, no document is returned.
+//
+// Parameters:
+// - doc: The pseudonymized JSON document
+// - paths: Paths given to PseudonymizeJSON, or a subset of them
+// - results: Results returned by PseudonymizeJSON
+//
+// Returns:
+//   - The document with the original values restored, re-encoded like the
+//     output of PseudonymizeJSON
+//   - error wrapping ErrPseudonymMismatch if a matched value has no Result or
+//     differs from its pseudonym, ErrInvalidJSONPath for a malformed path, an
+//     error for malformed JSON, or the error of the first failed revert
+func (s *Service) RevertJSON(doc []byte, paths []string, results map[string]*Result) ([]byte, error) {
+	root, fields, err := collectJSONPaths(doc, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		result := results[field.path]
+		if result == nil {
+			return nil, fmt.Errorf("%w: no result for path %s", ErrPseudonymMismatch, field.path)
+		}
+		// The value is not quoted: it may be an original rather than a pseudonym
+		if result.Pseudonym != field.value {
+			return nil, fmt.Errorf("%w: path %s does not hold pseudonym %s", ErrPseudonymMismatch, field.path, result.Pseudonym)
+		}
+	}
+
+	originals := make([]string, len(fields))
+	for i, field := range fields {
+		if originals[i], err = s.RevertResult(results[field.path], RevertOptions{}); err != nil {
+			return nil, fmt.Errorf("path %s: %w", field.path, err)
+		}
+	}
+	for i, field := range fields {
+		field.set(originals[i])
+	}
+	return encodeJSONDocument(root)
+}
+
+// collectJSONPaths decodes doc, keeping numbers as json.Number so they
+// re-encode unchanged, and collects the strings selected by paths
+func collectJSONPaths(doc []byte, paths []string) (root interface{}, fields []jsonField, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	if err := decoder.Decode(&root); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON document: %w", err)
+	}
+	if decoder.More() {
+		return nil, nil, fmt.Errorf("invalid JSON document: trailing data")
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		steps, err := parseJSONPath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Paths have at least one step, so the root itself is never replaced
+		if err := collectJSONFields(root, steps, "$", nil, seen, &fields); err != nil {
+			return nil, nil, err
+		}
+	}
+	return root, fields, nil
+}
+
+// encodeJSONDocument encodes root without escaping HTML characters or adding
+// a trailing newline
+func encodeJSONDocument(root interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// parseJSONPath splits a path such as "$.items[*].cpf" into its steps
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("%w: %q must start with $", ErrInvalidJSONPath, path)
+	}
+
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("%w: %q has an empty key", ErrInvalidJSONPath, path)
+			}
+			steps = append(steps, jsonPathStep{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %q has an unclosed [", ErrInvalidJSONPath, path)
+			}
+			inner := rest[1:end]
+			if inner == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("%w: %q has an invalid index %q", ErrInvalidJSONPath, path, inner)
+				}
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%w: %q has an unexpected %q", ErrInvalidJSONPath, path, rest[0])
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%w: %q selects the whole document", ErrInvalidJSONPath, path)
+	}
+	return steps, nil
+}
+
+// collectJSONFields appends the non-empty strings that steps select below
+// node, whose concrete path is prefix, to fields. set replaces node in its
+// parent; seen holds the paths already collected, so overlapping paths
+// pseudonymize each value once.
+func collectJSONFields(node interface{}, steps []jsonPathStep, prefix string, set func(string), seen map[string]bool, fields *[]jsonField) error {
+	if len(steps) == 0 {
+		switch value := node.(type) {
+		case nil:
+			return nil
+		case string:
+			if value != "" && !seen[prefix] {
+				seen[prefix] = true
+				*fields = append(*fields, jsonField{path: prefix, value: value, set: set})
+			}
+			return nil
+		default:
+			return fmt.Errorf("%w: %s is not a string", ErrInvalidJSONPath, prefix)
+		}
+	}
+
+	step, rest := steps[0], steps[1:]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if step.isIndex || step.wildcard {
+			return nil
+		}
+		child, ok := container[step.key]
+		if !ok {
+			return nil
+		}
+		setChild := func(v string) { container[step.key] = v }
+		return collectJSONFields(child, rest, prefix+"."+step.key, setChild, seen, fields)
+	case []interface{}:
+		if step.wildcard {
+			for i := range container {
+				if err := collectJSONElement(container, i, rest, prefix, seen, fields); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if step.isIndex && step.index < len(container) {
+			return collectJSONElement(container, step.index, rest, prefix, seen, fields)
+		}
+	}
+	return nil
+}
+
+// collectJSONElement is collectJSONFields for element i of an array
+func collectJSONElement(array []interface{}, i int, steps []jsonPathStep, prefix string, seen map[string]bool, fields *[]jsonField) error {
+	setElement := func(v string) { array[i] = v }
+	return collectJSONFields(array[i], steps, prefix+"["+strconv.Itoa(i)+"]", setElement, seen, fields)
+}
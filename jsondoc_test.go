@@ -0,0 +1,126 @@
+package pseudonymization
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizeJSON(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+	doc := []byte(`{
+		"customer": {"name": "Maria", "cpf": "52998224725", "age": 42},
+		"orders": [
+			{"id": 12345678901234567890, "buyer": {"email": "maria@example.com"}},
+			{"id": 2, "buyer": {"email": "ana@example.com"}},
+			{"id": 3, "buyer": {"email": null}}
+		],
+		"tags": ["a<b", ""]
+	}`)
+
+	out, results, err := svc.PseudonymizeJSON(doc, []string{
+		"$.customer.cpf",
+		"$.orders[*].buyer.email",
+		"$.orders[0].buyer.email", // Overlaps the wildcard
+		"$.tags[*]",
+		"$.missing.field",
+	}, "analytics", "crm")
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+
+	var decoded struct {
+		Customer map[string]interface{}
+		Orders   []struct {
+			ID    json.Number
+			Buyer struct{ Email *string }
+		}
+		Tags []string
+	}
+	assert.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, results["$.customer.cpf"].Pseudonym, decoded.Customer["cpf"])
+	assert.Equal(t, "Maria", decoded.Customer["name"])
+	assert.Equal(t, results["$.orders[0].buyer.email"].Pseudonym, *decoded.Orders[0].Buyer.Email)
+	assert.Equal(t, results["$.orders[1].buyer.email"].Pseudonym, *decoded.Orders[1].Buyer.Email)
+	assert.Nil(t, decoded.Orders[2].Buyer.Email)
+	assert.Equal(t, results["$.tags[0]"].Pseudonym, decoded.Tags[0])
+	assert.Equal(t, "", decoded.Tags[1])
+
+	// Numbers keep their exact text
+	assert.Contains(t, string(out), "12345678901234567890")
+
+	original, err := svc.Revert(results["$.orders[1].buyer.email"].EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "ana@example.com", original)
+	original, err = svc.Revert(results["$.tags[0]"].EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "a<b", original)
+}
+
+func TestPseudonymizeJSONErrors(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+	doc := []byte(`{"customer": {"cpf": "52998224725", "age": 42}}`)
+
+	for _, path := range []string{"customer.cpf", "$", "$.", "$.customer[", "$.customer[x]", "$..cpf", "$.customer.age"} {
+		t.Run(path, func(t *testing.T) {
+			_, _, err := svc.PseudonymizeJSON(doc, []string{path}, "analytics", "crm")
+			assert.ErrorIs(t, err, ErrInvalidJSONPath)
+		})
+	}
+
+	_, _, err := svc.PseudonymizeJSON([]byte(`{"cpf": `), []string{"$.cpf"}, "analytics", "crm")
+	assert.Error(t, err)
+	_, _, err = svc.PseudonymizeJSON([]byte(`{} {}`), []string{"$.cpf"}, "analytics", "crm")
+	assert.Error(t, err)
+
+	// A failing value leaves nothing half done
+	strict := NewService(randomKey(t, 32), WithStrictMode())
+	out, results, err := strict.PseudonymizeJSON([]byte(`{"a": "52998224725", "b": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`),
+		[]string{"$.a", "$.b"}, "analytics", "crm")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	assert.Contains(t, err.Error(), "path $.b")
+	assert.Nil(t, out)
+	assert.Nil(t, results)
+}
+
+func TestRevertJSON(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithAuditLogger(logger))
+	doc := []byte(`{"customer":{"cpf":"52998224725"},"orders":[{"email":"maria@example.com"},{"email":"ana@example.com"}]}`)
+	paths := []string{"$.customer.cpf", "$.orders[*].email"}
+
+	pseudonymized, results, err := svc.PseudonymizeJSON(doc, paths, "analytics", "crm")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(pseudonymized), "maria@example.com")
+
+	reverted, err := svc.RevertJSON(pseudonymized, paths, results)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(doc), string(reverted))
+	assert.Len(t, logger.events, 6) // 3 pseudonymized, 3 reverted
+
+	// A subset of the paths restores only those values
+	partial, err := svc.RevertJSON(pseudonymized, []string{"$.customer.cpf"}, results)
+	assert.NoError(t, err)
+	assert.Contains(t, string(partial), "52998224725")
+	assert.NotContains(t, string(partial), "maria@example.com")
+
+	// Reordered elements would restore into the wrong field
+	var swapped map[string]interface{}
+	assert.NoError(t, json.Unmarshal(pseudonymized, &swapped))
+	orders := swapped["orders"].([]interface{})
+	orders[0], orders[1] = orders[1], orders[0]
+	swappedDoc, err := json.Marshal(swapped)
+	assert.NoError(t, err)
+	_, err = svc.RevertJSON(swappedDoc, paths, results)
+	assert.ErrorIs(t, err, ErrPseudonymMismatch)
+	assert.Contains(t, err.Error(), "$.orders[0].email")
+
+	// Values without a Result, such as a document that was never
+	// pseudonymized, are rejected without being quoted
+	_, err = svc.RevertJSON(doc, paths, nil)
+	assert.ErrorIs(t, err, ErrPseudonymMismatch)
+	_, err = svc.RevertJSON(doc, paths, results)
+	assert.ErrorIs(t, err, ErrPseudonymMismatch)
+	assert.NotContains(t, err.Error(), "52998224725")
+	assert.Len(t, logger.events, 7)
+}
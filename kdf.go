@@ -0,0 +1,77 @@
+package pseudonymization
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// MinSaltLength is the minimum salt size accepted by DeriveKey
+const MinSaltLength = 16
+
+// KDFParams tunes the Argon2id key derivation of DeriveKeyWithParams. Higher
+// memory and iterations make brute-forcing the passphrase more expensive, at
+// the cost of a slower derivation.
+type KDFParams struct {
+	Memory      uint32 // Memory in KiB
+	Iterations  uint32 // Number of passes over the memory
+	Parallelism uint8  // Number of threads
+	KeyLength   uint32 // Derived key size: 16, 24 or 32 bytes
+}
+
+// DefaultKDFParams are the parameters used by DeriveKey: 64 MiB of memory,
+// 3 iterations and 4 threads, producing a 32-byte AES-256 key
+var DefaultKDFParams = KDFParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	KeyLength:   32,
+}
+
+// DeriveKey derives an encryption key for NewService from a human passphrase
+// using Argon2id with DefaultKDFParams.
+//
+// The salt must be random, at least MinSaltLength bytes long, and stored
+// alongside the pseudonymized data: it is not secret, but the same passphrase
+// and salt are needed to derive the same key again. Losing the salt makes
+// every encrypted value unrecoverable.
+//
+// Parameters:
+// - passphrase: The secret passphrase
+// - salt: Random salt of at least MinSaltLength bytes
+//
+// Returns:
+// - 32-byte key
+// - error if the passphrase is empty or the salt too short
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return DeriveKeyWithParams(passphrase, salt, DefaultKDFParams)
+}
+
+// DeriveKeyWithParams is like DeriveKey with custom Argon2id parameters. The
+// parameters must be stored with the salt, as changing any of them derives a
+// different key.
+//
+// Parameters:
+// - passphrase: The secret passphrase
+// - salt: Random salt of at least MinSaltLength bytes
+// - params: Argon2id cost parameters and key length
+//
+// Returns:
+// - Derived key of params.KeyLength bytes
+// - error if the passphrase is empty, the salt too short or params invalid
+func DeriveKeyWithParams(passphrase string, salt []byte, params KDFParams) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("%w: passphrase", ErrEmptyValue)
+	}
+	if len(salt) < MinSaltLength {
+		return nil, fmt.Errorf("%w: got %d bytes, want at least %d", ErrSaltTooShort, len(salt), MinSaltLength)
+	}
+	if err := validateKeyLength(make([]byte, params.KeyLength)); err != nil {
+		return nil, err
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 || params.Memory < 8*uint32(params.Parallelism) {
+		return nil, fmt.Errorf("%w: iterations and parallelism must be positive and memory at least 8 KiB per thread", ErrInvalidKDFParams)
+	}
+
+	return argon2.IDKey([]byte(passphrase), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength), nil
+}
@@ -0,0 +1,65 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveKey(t *testing.T) {
+	salt := randomKey(t, MinSaltLength)
+
+	key, err := DeriveKey("correct horse battery staple", salt)
+	assert.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	// Same passphrase and salt reproduce the key
+	again, err := DeriveKey("correct horse battery staple", salt)
+	assert.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	// A different salt or passphrase does not
+	other, err := DeriveKey("correct horse battery staple", randomKey(t, MinSaltLength))
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	other, err = DeriveKey("correct horse battery stapler", salt)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+
+	// The derived key works with NewService
+	svc, err := NewServiceWithError(key)
+	assert.NoError(t, err)
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	original, err := svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+}
+
+func TestDeriveKeyValidation(t *testing.T) {
+	salt := randomKey(t, MinSaltLength)
+	cheap := KDFParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLength: 16}
+
+	key, err := DeriveKeyWithParams("passphrase", salt, cheap)
+	assert.NoError(t, err)
+	assert.Len(t, key, 16)
+
+	_, err = DeriveKey("", salt)
+	assert.ErrorIs(t, err, ErrEmptyValue)
+	_, err = DeriveKey("passphrase", salt[:MinSaltLength-1])
+	assert.ErrorIs(t, err, ErrSaltTooShort)
+
+	invalid := cheap
+	invalid.KeyLength = 20
+	_, err = DeriveKeyWithParams("passphrase", salt, invalid)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+
+	for _, params := range []KDFParams{
+		{Memory: 64, Iterations: 0, Parallelism: 1, KeyLength: 32},
+		{Memory: 64, Iterations: 1, Parallelism: 0, KeyLength: 32},
+		{Memory: 16, Iterations: 1, Parallelism: 4, KeyLength: 32},
+	} {
+		_, err = DeriveKeyWithParams("passphrase", salt, params)
+		assert.ErrorIs(t, err, ErrInvalidKDFParams)
+	}
+}
@@ -0,0 +1,543 @@
+package pseudonymization
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxKeyVersion is the highest key version that fits in the one-byte header
+const maxKeyVersion = 255
+
+// DefaultNonceLimit is the number of encryptions a Service performs under its
+// active key before failing with ErrNonceLimitApproaching (see WithNonceLimit).
+//
+// AES-GCM nonces are 96 random bits, so after q encryptions the probability
+// that two of them collide is about q^2 / 2^97. A single collision under one
+// key reveals the XOR of two plaintexts and lets an attacker forge messages.
+// NIST SP 800-38D caps that probability at 2^-32, which allows
+// q = 2^32 encryptions (2^64 / 2^97 = 2^-33).
+const DefaultNonceLimit = 1 << 32
+
+// keyring holds the secret key material of a Service: the encryption keys,
+// indexed by version, and the optional HMAC key. All access goes through its
+// methods, which fail with ErrServiceClosed once close has wiped the keys.
+type keyring struct {
+	mu     sync.RWMutex
+	closed bool
+
+	hmacKey []byte
+	hashAlg HashAlgorithm
+
+	// nonceKey, when set, derives nonces from the plaintext instead of
+	// drawing them at random; see WithDeterministicEncryption
+	nonceKey []byte
+
+	mode   EncryptionMode
+	keys   map[int][]byte
+	aeads  map[int]cipher.AEAD
+	active int // version used to encrypt
+	legacy int // version used for ciphertexts without a version header
+
+	// versioned reports whether ciphertexts carry a key version header byte
+	versioned bool
+
+	// envelope reports whether new ciphertexts are written in the envelope
+	// format; both formats are always read
+	envelope bool
+
+	// nonces counts the random nonces drawn under the active key; once it
+	// exceeds nonceLimit (unless zero) encryption fails
+	nonces     atomic.Uint64
+	nonceLimit uint64
+
+	// random is the source of nonces, salts, tokens and random pseudonyms:
+	// crypto/rand.Reader unless replaced with WithRandomSource
+	random io.Reader
+}
+
+// newKeyring validates keys and builds an AEAD of the given mode for each of them
+func newKeyring(keys map[int][]byte, active int, versioned bool, mode EncryptionMode) (*keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("%w: active version %d", ErrUnknownKeyVersion, active)
+	}
+
+	ring := &keyring{
+		keys:       make(map[int][]byte, len(keys)),
+		aeads:      make(map[int]cipher.AEAD, len(keys)),
+		mode:       mode,
+		active:     active,
+		legacy:     lowestVersion(keys),
+		versioned:  versioned,
+		nonceLimit: DefaultNonceLimit,
+		random:     rand.Reader,
+	}
+
+	for version, key := range keys {
+		if version < 0 || version > maxKeyVersion {
+			return nil, fmt.Errorf("%w: %d out of range [0, %d]", ErrUnknownKeyVersion, version, maxKeyVersion)
+		}
+		if err := mode.validateKey(key); err != nil {
+			return nil, fmt.Errorf("key version %d: %w", version, err)
+		}
+
+		aead, err := mode.newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys[version] = append([]byte(nil), key...)
+		ring.aeads[version] = aead
+	}
+
+	return ring, nil
+}
+
+// seal encrypts plaintext under the active key and appends it to dst,
+// prefixed with the envelope header when enabled and the key version when the
+// keyring is versioned
+func (r *keyring) seal(dst, plaintext, aad []byte) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, ErrServiceClosed
+	}
+
+	if err := r.useNonce(); err != nil {
+		return nil, err
+	}
+
+	if r.envelope {
+		dst = append(dst, envelopeVersion, r.mode.algorithmID())
+	}
+	if r.versioned {
+		dst = append(dst, byte(r.active))
+	}
+	if r.nonceKey != nil {
+		return sealDeterministic(r.aeads[r.active], r.nonceKey, dst, plaintext, aad), nil
+	}
+	return sealWith(r.aeads[r.active], r.random, dst, plaintext, aad)
+}
+
+// useNonce counts one random nonce drawn under the active key and fails with
+// ErrNonceLimitApproaching past the limit. AES-SIV draws no nonces, so it is
+// never limited.
+func (r *keyring) useNonce() error {
+	if r.mode == ModeSIV {
+		return nil
+	}
+	if n := r.nonces.Add(1); r.nonceLimit != 0 && n > r.nonceLimit {
+		return fmt.Errorf("%w: %d encryptions under key version %d", ErrNonceLimitApproaching, r.nonceLimit, r.active)
+	}
+	return nil
+}
+
+// open decrypts data in the envelope or the legacy format, selecting the key
+// from the version header. Data without a recognizable header is decrypted
+// with the legacy key.
+func (r *keyring) open(data, aad []byte) ([]byte, error) {
+	plaintext, _, err := r.openDetailed(data, aad)
+	return plaintext, err
+}
+
+// cipherHeader describes how a ciphertext was read
+type cipherHeader struct {
+	mode      EncryptionMode
+	version   int // key version, or -1 if unknown
+	enveloped bool
+}
+
+// openDetailed is open, also reporting the header that was used to decrypt
+// data or, on failure, the header data appears to carry
+func (r *keyring) openDetailed(data, aad []byte) ([]byte, cipherHeader, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, cipherHeader{mode: r.mode, version: -1}, ErrServiceClosed
+	}
+
+	if len(data) >= envelopeHeaderSize && data[0] == envelopeVersion {
+		if mode, ok := modeFromAlgorithmID(data[1]); ok {
+			var err error
+			if mode == r.mode {
+				var plaintext []byte
+				var version int
+				if plaintext, version, err = r.openPayload(data[envelopeHeaderSize:], aad); err == nil {
+					return plaintext, cipherHeader{mode: mode, version: version, enveloped: true}, nil
+				}
+			} else {
+				err = fmt.Errorf("%w: encrypted with %s, service uses %s", ErrDecryptionFailed, mode, r.mode)
+			}
+
+			// Not an envelope after all, or a corrupted one: report the
+			// envelope error unless the legacy reading succeeds
+			if plaintext, version, legacyErr := r.openPayload(data, aad); legacyErr == nil {
+				return plaintext, cipherHeader{mode: r.mode, version: version}, nil
+			}
+			return nil, cipherHeader{mode: mode, version: r.headerVersion(data[envelopeHeaderSize:]), enveloped: true}, err
+		}
+	}
+
+	plaintext, version, err := r.openPayload(data, aad)
+	if err != nil {
+		version = r.headerVersion(data)
+	}
+	return plaintext, cipherHeader{mode: r.mode, version: version}, err
+}
+
+// openPayload decrypts a ciphertext without envelope header and reports the
+// key version that authenticated it. The caller holds r.mu.
+func (r *keyring) openPayload(data, aad []byte) ([]byte, int, error) {
+	if r.versioned && len(data) > 0 {
+		if aead, ok := r.aeads[int(data[0])]; ok {
+			if plaintext, err := openWith(aead, data[1:], aad); err == nil {
+				return plaintext, int(data[0]), nil
+			}
+		}
+	}
+	plaintext, err := openWith(r.aeads[r.legacy], data, aad)
+	return plaintext, r.legacy, err
+}
+
+// headerVersion returns the key version named by the header of a ciphertext
+// without envelope header: its first byte for a versioned keyring, when that
+// version exists, and the legacy version for an unversioned one. It returns
+// -1 when the version cannot be told. The caller holds r.mu.
+func (r *keyring) headerVersion(data []byte) int {
+	if !r.versioned {
+		return r.legacy
+	}
+	if len(data) > 0 {
+		if _, ok := r.aeads[int(data[0])]; ok {
+			return int(data[0])
+		}
+	}
+	return -1
+}
+
+// activeAEAD returns the cipher and version of the active key
+func (r *keyring) activeAEAD() (cipher.AEAD, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, 0, ErrServiceClosed
+	}
+	return r.aeads[r.active], r.active, nil
+}
+
+// aeadFor returns the cipher of the given key version
+func (r *keyring) aeadFor(version int) (cipher.AEAD, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, ErrServiceClosed
+	}
+
+	aead, ok := r.aeads[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+	return aead, nil
+}
+
+// digest returns the HMAC of data under the configured hash algorithm, or its
+// plain hash when no HMAC key is configured
+func (r *keyring) digest(data []byte) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, ErrServiceClosed
+	}
+
+	if r.hmacKey == nil {
+		return r.hashAlg.sum(data), nil
+	}
+
+	mac := hmac.New(r.hashAlg.newHash(), r.hmacKey)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// keyed reports whether an HMAC key is configured
+func (r *keyring) keyed() bool {
+	return r.hmacKey != nil
+}
+
+// close overwrites every key with zeros and drops the ciphers built from
+// them. It waits for in-flight operations to finish.
+func (r *keyring) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
+	for _, key := range r.keys {
+		wipe(key)
+	}
+	wipe(r.hmacKey)
+	wipe(r.nonceKey)
+	r.keys = nil
+	r.aeads = nil
+	r.closed = true
+}
+
+// ReEncrypt migrates a value encrypted under oldKey to the service's active
+// key in a single call, so the plaintext never reaches the caller. The
+// intermediate plaintext buffer is zeroed before returning.
+//
+// The old ciphertext may be unversioned (produced by NewService) or carry a
+// key version header (produced by NewServiceWithKeyring), in the legacy or
+// the envelope format. Values bound to a purpose and system with
+// WithPurposeBinding cannot be re-encrypted this way.
+//
+// Parameters:
+// - encryptedValue: Base64-encoded value encrypted under oldKey
+// - oldKey: The AES-GCM key the value is currently encrypted with
+//
+// Returns:
+// - Base64-encoded value encrypted under the service's active key
+// - error if oldKey is invalid or fails to authenticate the ciphertext
+func (s *Service) ReEncrypt(encryptedValue string, oldKey []byte) (string, error) {
+	if err := validateKeyLength(oldKey); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(oldKey)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := decodeCiphertext(encryptedValue)
+	if err != nil {
+		return "", err
+	}
+
+	var plaintext []byte
+	for _, candidate := range framings(data) {
+		if plaintext, err = openWith(aead, candidate, s.tenantAAD(nil)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("old key failed to authenticate ciphertext: %w", err)
+	}
+	defer wipe(plaintext)
+
+	encrypted, err := s.encryptBytesWithAAD(plaintext, nil)
+	if err != nil {
+		return "", fmt.Errorf("encryption failed: %w", err)
+	}
+	return encrypted, nil
+}
+
+// ReEncryptWith migrates a value encrypted by the receiver to target, e.g.
+// from a staging service to a production one: it decrypts with the
+// receiver's keys and encrypts with target's active key, mode, tenant and
+// ciphertext encoding, so the two services may use different algorithms.
+// The plaintext never reaches the caller and its buffer is zeroed before
+// returning.
+//
+// Like ReEncrypt, it records no audit event, and values bound to a purpose
+// and system with WithPurposeBinding cannot be migrated this way.
+//
+// Parameters:
+// - target: The service to encrypt for
+// - encryptedValue: Base64-encoded value encrypted by the receiver
+//
+// Returns:
+// - Base64-encoded value encrypted by target
+// - error if the receiver fails to decrypt or target fails to encrypt
+func (s *Service) ReEncryptWith(target *Service, encryptedValue string) (string, error) {
+	plaintext, err := s.decryptBytesWithAAD(encryptedValue, nil)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(plaintext)
+
+	encrypted, err := target.encryptBytesWithAAD(plaintext, nil)
+	if err != nil {
+		return "", fmt.Errorf("encryption failed: %w", err)
+	}
+	return encrypted, nil
+}
+
+// RevertAny decrypts a value encrypted under one of several AES-GCM keys,
+// without knowing which, and reports the key that authenticated it. It suits
+// lazy migrations during a multi-step rotation, where records are
+// re-encrypted as they are read.
+//
+// Every key is tried, with and without key version and envelope headers, even
+// after one has matched, so the time taken depends only on the number of
+// keys and not on which of them matched. Like Revert, a successful call is
+// audited. Values bound to a purpose and system with WithPurposeBinding
+// cannot be reverted this way.
+//
+// Parameters:
+// - encryptedValue: Base64-encoded encrypted value
+// - keys: Candidate AES-GCM keys, e.g. the current key followed by older ones
+//
+// Returns:
+//   - Original plaintext value
+//   - Index in keys of the key that authenticated the value, or -1
+//   - error wrapping ErrInvalidKeyLength for an invalid key, or
+//     ErrDecryptionFailed if no key authenticates the value
+func (s *Service) RevertAny(encryptedValue string, keys [][]byte) (plaintext string, keyIndex int, err error) {
+	defer s.observeRevert(time.Now(), &err)
+
+	if encryptedValue == "" {
+		return "", -1, ErrNotReversible
+	}
+	if err := s.allowRevert(context.Background()); err != nil {
+		return "", -1, err
+	}
+	aeads := make([]cipher.AEAD, len(keys))
+	for i, key := range keys {
+		if err := validateKeyLength(key); err != nil {
+			return "", -1, fmt.Errorf("key %d: %w", i, err)
+		}
+		if aeads[i], err = newAEAD(key); err != nil {
+			return "", -1, err
+		}
+	}
+
+	data, err := decodeCiphertext(encryptedValue)
+	if err != nil {
+		return "", -1, err
+	}
+
+	aad := s.tenantAAD(nil)
+	var opened []byte
+	keyIndex = -1
+	for i, aead := range aeads {
+		// Try every framing for every key instead of stopping at the first
+		// match, so timing does not reveal the matching key
+		for _, candidate := range framings(data) {
+			if out, err := openWith(aead, candidate, aad); err == nil && keyIndex < 0 {
+				opened, keyIndex = out, i
+			}
+		}
+	}
+	if keyIndex < 0 {
+		return "", -1, fmt.Errorf("%w: no key authenticated the ciphertext", ErrDecryptionFailed)
+	}
+	defer wipe(opened)
+
+	plaintext = string(opened)
+	hash, _ := s.originalHash(plaintext)
+	s.audit(context.Background(), AuditEvent{
+		Operation:    OperationRevert,
+		OriginalHash: hash,
+	})
+	return plaintext, keyIndex, nil
+}
+
+// framings returns the readings of an AES-GCM ciphertext of unknown origin:
+// as it is and without a key version header, plus the same two without the
+// envelope header when data starts with one
+func framings(data []byte) [][]byte {
+	candidates := [][]byte{data, data[min(1, len(data)):]}
+	if payload, ok := splitEnvelope(data, ModeGCM); ok {
+		candidates = append(candidates, payload, payload[min(1, len(payload)):])
+	}
+	return candidates
+}
+
+// wipe overwrites b with zeros
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// newAEAD builds the AES-GCM cipher for key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealWith encrypts plaintext with aead under a nonce read from random and
+// appends nonce || ciphertext to dst
+func sealWith(aead cipher.AEAD, random io.Reader, dst, plaintext, aad []byte) ([]byte, error) {
+	start := len(dst)
+	dst = slices.Grow(dst, aead.NonceSize()+len(plaintext)+aead.Overhead())
+	dst = dst[:start+aead.NonceSize()]
+
+	nonce := dst[start:]
+	if _, err := io.ReadFull(random, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(dst, nonce, plaintext, aad), nil
+}
+
+// deterministicNonceLabel separates the nonce key from other uses of the
+// encryption key
+const deterministicNonceLabel = "pseudonymization deterministic nonce v1"
+
+// deriveNonceKey derives the key that deterministic nonces are computed
+// under from an encryption key
+func deriveNonceKey(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(deterministicNonceLabel))
+	return mac.Sum(nil)
+}
+
+// sealDeterministic is sealWith under a synthetic nonce: the truncated
+// HMAC-SHA256 of aad and plaintext under nonceKey. Equal inputs give equal
+// outputs, and distinct inputs get nonces that collide no more often than
+// random ones. The aad is length-prefixed so that moving bytes between aad
+// and plaintext changes the nonce.
+func sealDeterministic(aead cipher.AEAD, nonceKey, dst, plaintext, aad []byte) []byte {
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(aad)))
+
+	mac := hmac.New(sha256.New, nonceKey)
+	mac.Write(size[:])
+	mac.Write(aad)
+	mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:aead.NonceSize()]
+
+	dst = slices.Grow(dst, len(nonce)+len(plaintext)+aead.Overhead())
+	return aead.Seal(append(dst, nonce...), nonce, plaintext, aad)
+}
+
+// openWith decrypts nonce || ciphertext with aead
+func openWith(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(data) < nonceSize+aead.Overhead() {
+		return nil, ErrCiphertextTooShort
+	}
+
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+	return plaintext, nil
+}
+
+// lowestVersion returns the smallest version in keys
+func lowestVersion(keys map[int][]byte) int {
+	versions := make([]int, 0, len(keys))
+	for version := range keys {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	if len(versions) == 0 {
+		return 0
+	}
+	return versions[0]
+}
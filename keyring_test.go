@@ -0,0 +1,374 @@
+package pseudonymization
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomKey(t testing.TB, size int) []byte {
+	key := make([]byte, size)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey := randomKey(t, 32)
+	newKey := randomKey(t, 32)
+
+	// Values encrypted before rotation, without a version header
+	legacy := NewService(oldKey)
+	legacyResult, err := legacy.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	v1, err := NewServiceWithKeyring(map[int][]byte{1: oldKey}, 1)
+	assert.NoError(t, err)
+	v1Result, err := v1.Pseudonymize("11144477735", "test", "test")
+	assert.NoError(t, err)
+
+	// Rotate to version 2
+	v2, err := NewServiceWithKeyring(map[int][]byte{1: oldKey, 2: newKey}, 2)
+	assert.NoError(t, err)
+	v2Result, err := v2.Pseudonymize("user@example.com", "test", "test")
+	assert.NoError(t, err)
+
+	for value, encrypted := range map[string]string{
+		"52998224725":      legacyResult.EncryptedValue,
+		"11144477735":      v1Result.EncryptedValue,
+		"user@example.com": v2Result.EncryptedValue,
+	} {
+		original, err := v2.Revert(encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, value, original)
+	}
+
+	// The new key alone cannot revert values encrypted with the old key
+	onlyNew, err := NewServiceWithKeyring(map[int][]byte{2: newKey}, 2)
+	assert.NoError(t, err)
+	_, err = onlyNew.Revert(v1Result.EncryptedValue)
+	assert.Error(t, err)
+}
+
+func TestKeyringLegacyKeyVersion(t *testing.T) {
+	oldKey := randomKey(t, 32)
+	newKey := randomKey(t, 32)
+
+	legacyResult, err := NewService(newKey).Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	// Default legacy key is the lowest version, which is the wrong one here
+	svc, err := NewServiceWithKeyring(map[int][]byte{1: oldKey, 2: newKey}, 2)
+	assert.NoError(t, err)
+	_, err = svc.Revert(legacyResult.EncryptedValue)
+	assert.Error(t, err)
+
+	svc, err = NewServiceWithKeyring(map[int][]byte{1: oldKey, 2: newKey}, 2, WithLegacyKeyVersion(2))
+	assert.NoError(t, err)
+	original, err := svc.Revert(legacyResult.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+}
+
+func TestKeyringValidation(t *testing.T) {
+	key := randomKey(t, 32)
+
+	_, err := NewServiceWithKeyring(map[int][]byte{1: key}, 2)
+	assert.ErrorIs(t, err, ErrUnknownKeyVersion)
+
+	_, err = NewServiceWithKeyring(map[int][]byte{1: key, 2: key[:20]}, 1)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+
+	_, err = NewServiceWithKeyring(map[int][]byte{256: key}, 256)
+	assert.Error(t, err)
+
+	_, err = NewServiceWithKeyring(map[int][]byte{1: key}, 1, WithLegacyKeyVersion(3))
+	assert.ErrorIs(t, err, ErrUnknownKeyVersion)
+}
+
+func TestReEncrypt(t *testing.T) {
+	oldKey := randomKey(t, 32)
+	newKey := randomKey(t, 32)
+
+	oldSvc := NewService(oldKey)
+	result, err := oldSvc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	newSvc, err := NewServiceWithKeyring(map[int][]byte{2: newKey}, 2)
+	assert.NoError(t, err)
+
+	rotated, err := newSvc.ReEncrypt(result.EncryptedValue, oldKey)
+	assert.NoError(t, err)
+	assert.NotEqual(t, result.EncryptedValue, rotated)
+
+	original, err := newSvc.Revert(rotated)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Versioned ciphertexts can be rotated as well
+	versioned, err := NewServiceWithKeyring(map[int][]byte{1: oldKey}, 1)
+	assert.NoError(t, err)
+	encrypted, err := versioned.encrypt("11144477735")
+	assert.NoError(t, err)
+	rotated, err = newSvc.ReEncrypt(encrypted, oldKey)
+	assert.NoError(t, err)
+	original, err = newSvc.Revert(rotated)
+	assert.NoError(t, err)
+	assert.Equal(t, "11144477735", original)
+
+	// The wrong old key fails authentication
+	_, err = newSvc.ReEncrypt(result.EncryptedValue, randomKey(t, 32))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "old key failed to authenticate")
+
+	_, err = newSvc.ReEncrypt(result.EncryptedValue, oldKey[:10])
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
+
+func TestReEncryptWith(t *testing.T) {
+	staging := NewService(randomKey(t, 32))
+	production, err := NewServiceWithMode(randomKey(t, 32), ModeChaCha20Poly1305,
+		WithCiphertextEncoding(base64.RawURLEncoding))
+	assert.NoError(t, err)
+	production = production.WithTenant("acme")
+
+	result, err := staging.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	migrated, err := staging.ReEncryptWith(production, result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.NotContains(t, migrated, "=")
+
+	original, err := production.Revert(migrated)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+	_, err = staging.Revert(migrated)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// And back, from ChaCha20-Poly1305 to AES-GCM
+	back, err := production.ReEncryptWith(staging, migrated)
+	assert.NoError(t, err)
+	original, err = staging.Revert(back)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Only values the receiver can decrypt are migrated
+	_, err = production.ReEncryptWith(staging, result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestRevertAny(t *testing.T) {
+	keys := [][]byte{randomKey(t, 32), randomKey(t, 16), randomKey(t, 32)}
+
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithAuditLogger(logger))
+
+	for i, key := range keys {
+		result, err := NewService(key).Pseudonymize("52998224725", "test", "test")
+		assert.NoError(t, err)
+
+		original, keyIndex, err := svc.RevertAny(result.EncryptedValue, keys)
+		assert.NoError(t, err)
+		assert.Equal(t, "52998224725", original)
+		assert.Equal(t, i, keyIndex)
+	}
+	assert.Len(t, logger.events, len(keys))
+	assert.Equal(t, OperationRevert, logger.events[0].Operation)
+
+	// Versioned ciphertexts are recognized as well
+	versioned, err := NewServiceWithKeyring(map[int][]byte{7: keys[2]}, 7)
+	assert.NoError(t, err)
+	encrypted, err := versioned.Encrypt("11144477735")
+	assert.NoError(t, err)
+	original, keyIndex, err := svc.RevertAny(encrypted, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, "11144477735", original)
+	assert.Equal(t, 2, keyIndex)
+
+	// No matching key
+	_, keyIndex, err = svc.RevertAny(encrypted, keys[:2])
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	assert.Equal(t, -1, keyIndex)
+	_, _, err = svc.RevertAny(encrypted, nil)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, _, err = svc.RevertAny(encrypted, [][]byte{keys[0], keys[0][:10]})
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, _, err = svc.RevertAny("not base64!", keys)
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+	_, _, err = svc.RevertAny("", keys)
+	assert.ErrorIs(t, err, ErrNotReversible)
+}
+
+func TestNonceLimit(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithNonceLimit(3))
+
+	var results []*Result
+	for i := 0; i < 3; i++ {
+		result, err := svc.Pseudonymize("52998224725", "test", "test")
+		assert.NoError(t, err)
+		results = append(results, result)
+	}
+
+	_, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrNonceLimitApproaching)
+	_, err = svc.Encrypt("52998224725")
+	assert.ErrorIs(t, err, ErrNonceLimitApproaching)
+	assert.ErrorIs(t, svc.EncryptStream(io.Discard, strings.NewReader("x")), ErrNonceLimitApproaching)
+
+	// Decryption is unaffected
+	for _, result := range results {
+		_, err := svc.Revert(result.EncryptedValue)
+		assert.NoError(t, err)
+	}
+
+	// The default limit is 2^32 and zero disables it
+	assert.Equal(t, uint64(DefaultNonceLimit), NewService(randomKey(t, 32)).ring.nonceLimit)
+	unlimited := NewService(randomKey(t, 32), WithNonceLimit(0))
+	unlimited.ring.nonces.Store(DefaultNonceLimit)
+	_, err = unlimited.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	// AES-SIV draws no random nonces
+	siv, err := NewServiceWithMode(randomKey(t, 64), ModeSIV, WithNonceLimit(1))
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := siv.Pseudonymize("52998224725", "test", "test")
+		assert.NoError(t, err)
+	}
+}
+
+func TestNonceLimitConcurrent(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithNonceLimit(100))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if _, err := svc.Encrypt("52998224725"); err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, succeeded)
+}
+
+func TestDeterministicEncryption(t *testing.T) {
+	key := randomKey(t, 32)
+	svc := NewService(key, WithDeterministicEncryption(), WithPurposeBinding())
+
+	first, err := svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	second, err := svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	assert.Equal(t, first.EncryptedValue, second.EncryptedValue)
+
+	// Different values, purposes, TTLs and keys give different ciphertexts
+	other, err := svc.Pseudonymize("52998224726", "billing", "erp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, other.EncryptedValue)
+	other, err = svc.Pseudonymize("52998224725", "marketing", "erp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, other.EncryptedValue)
+	other, err = svc.PseudonymizeWithOptions("52998224725", PseudonymizeOptions{Purpose: "billing", System: "erp", TTL: time.Hour})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, other.EncryptedValue)
+	other, err = NewService(randomKey(t, 32), WithDeterministicEncryption(), WithPurposeBinding()).Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, other.EncryptedValue)
+
+	// Ciphertexts are ordinary AES-GCM: a service without the option reads them
+	original, err := NewService(key, WithPurposeBinding()).RevertWithContext(first.EncryptedValue, "billing", "erp")
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Also with ChaCha20-Poly1305 and a keyring
+	chacha, err := NewServiceWithMode(key, ModeChaCha20Poly1305, WithDeterministicEncryption())
+	assert.NoError(t, err)
+	a, err := chacha.Encrypt("52998224725")
+	assert.NoError(t, err)
+	b, err := chacha.Encrypt("52998224725")
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	ring, err := NewServiceWithKeyring(map[int][]byte{1: randomKey(t, 32), 2: key}, 2, WithDeterministicEncryption())
+	assert.NoError(t, err)
+	a, err = ring.Encrypt("52998224725")
+	assert.NoError(t, err)
+	b, err = ring.Encrypt("52998224725")
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+	original, err = ring.Decrypt(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+}
+
+func TestClose(t *testing.T) {
+	key := randomKey(t, 32)
+	svc := NewService(key, WithHMACKey(randomKey(t, 32)))
+	assert.Implements(t, (*io.Closer)(nil), svc)
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.Close())
+	assert.NoError(t, svc.Close())
+
+	// Key material held by the service is wiped
+	assert.Nil(t, svc.ring.keys)
+	assert.Equal(t, make([]byte, 32), svc.ring.hmacKey)
+
+	// The caller's copy of the key is left untouched
+	assert.NotEqual(t, make([]byte, 32), key)
+
+	_, err = svc.Pseudonymize("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+	_, err = svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrServiceClosed)
+	assert.Empty(t, svc.HashKeyed("52998224725"))
+	assert.False(t, svc.VerifyHash("52998224725", result.OriginalHash))
+	assert.ErrorIs(t, svc.EncryptStream(io.Discard, strings.NewReader("x")), ErrServiceClosed)
+}
+
+func TestCloseConcurrentWithOperations(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				result, err := svc.Pseudonymize("52998224725", "test", "test")
+				if err != nil {
+					assert.ErrorIs(t, err, ErrServiceClosed)
+					return
+				}
+				if _, err := svc.Revert(result.EncryptedValue); err != nil {
+					assert.ErrorIs(t, err, ErrServiceClosed)
+					return
+				}
+			}
+		}()
+	}
+
+	assert.NoError(t, svc.Close())
+	wg.Wait()
+}
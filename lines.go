@@ -0,0 +1,67 @@
+package pseudonymization
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PseudonymizeLines pseudonymizes a file of one value per line, such as a
+// list of CPFs, without holding it in memory: each non-blank line read from
+// src is pseudonymized with Pseudonymize and written to dst as a CSV row of
+// original_hash,pseudonym,encrypted (no header row). Both LF and CRLF line
+// endings are accepted, a missing final newline is fine, and blank lines,
+// including lines of only spaces, are skipped. Other lines are used as they
+// are, surrounding spaces included.
+//
+// Rows are written as lines are read, so when an error stops the run, dst
+// already holds the rows of the lines before it.
+//
+// Parameters:
+// - src: Source of the values, one per line
+// - dst: Destination of the CSV rows
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+//   - error if reading or writing fails, or the error of the first line that
+//     failed to pseudonymize, prefixed with its line number
+func (s *Service) PseudonymizeLines(src io.Reader, dst io.Writer, purpose, system string) error {
+	writer := csv.NewWriter(dst)
+	err := s.pseudonymizeLines(bufio.NewReader(src), writer, purpose, system)
+
+	// Flush even on error, so dst holds every row written so far
+	writer.Flush()
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// pseudonymizeLines is PseudonymizeLines without the final flush
+func (s *Service) pseudonymizeLines(reader *bufio.Reader, writer *csv.Writer, purpose, system string) error {
+	for number := 1; ; number++ {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return readErr
+		}
+
+		value := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if strings.TrimSpace(value) != "" {
+			result, err := s.Pseudonymize(value, purpose, system)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", number, err)
+			}
+			if err := writer.Write([]string{result.OriginalHash, result.Pseudonym, result.EncryptedValue}); err != nil {
+				return err
+			}
+		}
+
+		if readErr != nil {
+			return nil
+		}
+	}
+}
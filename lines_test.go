@@ -0,0 +1,69 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizeLines(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	// CRLF and LF endings, blank lines and no final newline
+	input := "52998224725\r\n\r\n111.444.777-35\n   \n\nmaria@example.com"
+	var out bytes.Buffer
+	assert.NoError(t, svc.PseudonymizeLines(strings.NewReader(input), &out, "test", "test"))
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	for i, original := range []string{"52998224725", "111.444.777-35", "maria@example.com"} {
+		assert.Len(t, rows[i], 3)
+		assert.True(t, svc.VerifyHash(original, rows[i][0]))
+		assert.NotEmpty(t, rows[i][1])
+
+		reverted, err := svc.Revert(rows[i][2])
+		assert.NoError(t, err)
+		assert.Equal(t, original, reverted)
+	}
+
+	// Empty input writes nothing
+	out.Reset()
+	assert.NoError(t, svc.PseudonymizeLines(strings.NewReader(""), &out, "test", "test"))
+	assert.Empty(t, out.String())
+}
+
+func TestPseudonymizeLinesErrors(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithMaxValueLength(11))
+
+	// Rows before the failing line are kept
+	var out bytes.Buffer
+	err := svc.PseudonymizeLines(strings.NewReader("52998224725\n\n111.444.777-35\n"), &out, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	assert.Contains(t, err.Error(), "line 3")
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+
+	// Read errors are returned as they are
+	readErr := errors.New("disk on fire")
+	err = svc.PseudonymizeLines(&failingLineReader{data: "52998224725\n", err: readErr}, &out, "test", "test")
+	assert.ErrorIs(t, err, readErr)
+}
+
+// failingLineReader returns data, then err
+type failingLineReader struct {
+	data string
+	err  error
+}
+
+func (r *failingLineReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
@@ -0,0 +1,114 @@
+package pseudonymization
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// EncryptionMode selects the cipher a Service uses for EncryptedValue
+type EncryptionMode int
+
+const (
+	// ModeGCM encrypts with AES-GCM under a random nonce, so encrypting the
+	// same value twice gives different ciphertexts. This is the default.
+	ModeGCM EncryptionMode = iota
+
+	// ModeSIV encrypts with AES-SIV (RFC 5297), a deterministic and
+	// nonce-misuse-resistant mode: the same value (under the same purpose and
+	// system, with purpose binding) always gives the same ciphertext, so the
+	// encrypted column itself can be joined or indexed. This leaks which
+	// records hold equal values to anyone who can see the ciphertexts.
+	// Requires a 64-byte key.
+	ModeSIV
+
+	// ModeChaCha20Poly1305 encrypts with ChaCha20-Poly1305 (RFC 8439) under a
+	// random 96-bit nonce, with the same ciphertext framing as ModeGCM. It is
+	// faster than AES-GCM, and constant-time, on CPUs without AES hardware
+	// acceleration such as many ARM and embedded targets. Requires a 32-byte
+	// key.
+	ModeChaCha20Poly1305
+)
+
+// String returns the name of the mode
+func (m EncryptionMode) String() string {
+	switch m {
+	case ModeGCM:
+		return "AES-GCM"
+	case ModeSIV:
+		return "AES-SIV"
+	case ModeChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	default:
+		return fmt.Sprintf("EncryptionMode(%d)", int(m))
+	}
+}
+
+// validateKey checks that key has a valid size for the mode and is not
+// obviously weak (see checkKeyStrength)
+func (m EncryptionMode) validateKey(key []byte) error {
+	if len(key) == 0 {
+		return errMissingKey
+	}
+	switch m {
+	case ModeGCM:
+		if err := validateKeyLength(key); err != nil {
+			return err
+		}
+	case ModeSIV:
+		if len(key) != sivKeySize {
+			return fmt.Errorf("%w: got %d bytes, want %d for %s", ErrInvalidKeyLength, len(key), sivKeySize, m)
+		}
+	case ModeChaCha20Poly1305:
+		if len(key) != chacha20poly1305.KeySize {
+			return fmt.Errorf("%w: got %d bytes, want %d for %s", ErrInvalidKeyLength, len(key), chacha20poly1305.KeySize, m)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMode, m)
+	}
+	return checkKeyStrength(key)
+}
+
+// newAEAD builds the cipher of the mode for key
+func (m EncryptionMode) newAEAD(key []byte) (cipher.AEAD, error) {
+	switch m {
+	case ModeSIV:
+		return newSIV(key)
+	case ModeChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	default:
+		return newAEAD(key)
+	}
+}
+
+// NewServiceWithMode creates a pseudonymization service that encrypts with
+// the given mode. NewService is equivalent to NewServiceWithMode with ModeGCM.
+//
+// Security tradeoff: ModeSIV makes EncryptedValue deterministic, which lets
+// the encrypted column be joined across datasets without a separate
+// deterministic pseudonym, but reveals which records share the same value.
+// Use it only when that equality leak is acceptable.
+//
+// Parameters:
+//   - encryptionKey: 16, 24 or 32 bytes for ModeGCM; 64 bytes for ModeSIV;
+//     32 bytes for ModeChaCha20Poly1305
+//   - mode: ModeGCM, ModeSIV or ModeChaCha20Poly1305
+//   - opts: optional settings such as WithHMACKey
+//
+// Returns:
+//   - Service ready for use
+//   - error wrapping ErrInvalidKeyLength if the key does not fit the mode,
+//     ErrWeakKey if it is all zeros or a repeated pattern, or
+//     ErrUnsupportedMode for an unknown mode
+func NewServiceWithMode(encryptionKey []byte, mode EncryptionMode, opts ...Option) (*Service, error) {
+	if err := mode.validateKey(encryptionKey); err != nil {
+		return nil, err
+	}
+
+	ring, err := newKeyring(map[int][]byte{0: encryptionKey}, 0, false, mode)
+	if err != nil {
+		return nil, err
+	}
+	return newService(ring, opts)
+}
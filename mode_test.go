@@ -0,0 +1,117 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServiceWithMode(t *testing.T) {
+	_, err := NewServiceWithMode(randomKey(t, 32), ModeSIV)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, err = NewServiceWithMode(randomKey(t, 64), ModeGCM)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, err = NewServiceWithMode(randomKey(t, 32), EncryptionMode(7))
+	assert.ErrorIs(t, err, ErrUnsupportedMode)
+
+	gcm, err := NewServiceWithMode(randomKey(t, 32), ModeGCM)
+	assert.NoError(t, err)
+	first, err := gcm.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	second, err := gcm.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, second.EncryptedValue)
+}
+
+func TestModeSIV(t *testing.T) {
+	key := randomKey(t, 64)
+	svc, err := NewServiceWithMode(key, ModeSIV, WithPurposeBinding())
+	assert.NoError(t, err)
+
+	first, err := svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	second, err := svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+
+	// Deterministic: equal values give equal ciphertexts...
+	assert.Equal(t, first.EncryptedValue, second.EncryptedValue)
+	assert.NotEqual(t, first.Pseudonym, second.Pseudonym)
+
+	// ...but not across values or purposes
+	other, err := svc.Pseudonymize("52998224726", "billing", "erp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, other.EncryptedValue)
+	other, err = svc.Pseudonymize("52998224725", "marketing", "erp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, other.EncryptedValue)
+
+	original, err := svc.RevertWithContext(first.EncryptedValue, "billing", "erp")
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+	_, err = svc.RevertWithContext(first.EncryptedValue, "marketing", "erp")
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// A GCM service cannot read SIV ciphertexts
+	_, err = NewService(key[:32]).Revert(first.EncryptedValue)
+	assert.Error(t, err)
+
+	_, err = svc.Revert("AAAA")
+	assert.ErrorIs(t, err, ErrCiphertextTooShort)
+}
+
+func TestModeChaCha20Poly1305(t *testing.T) {
+	_, err := NewServiceWithMode(randomKey(t, 16), ModeChaCha20Poly1305)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+
+	key := randomKey(t, 32)
+	svc, err := NewServiceWithMode(key, ModeChaCha20Poly1305)
+	assert.NoError(t, err)
+
+	first, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	second, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.EncryptedValue, second.EncryptedValue)
+
+	original, err := svc.Revert(first.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Same framing as AES-GCM: nonce (12 bytes) || ciphertext || tag (16 bytes)
+	gcm, err := NewService(key).Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, len(gcm.EncryptedValue), len(first.EncryptedValue))
+
+	// ...but the algorithms are not interchangeable
+	_, err = NewService(key).Revert(first.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestEncryptionModeString(t *testing.T) {
+	assert.Equal(t, "AES-GCM", ModeGCM.String())
+	assert.Equal(t, "AES-SIV", ModeSIV.String())
+	assert.Equal(t, "ChaCha20-Poly1305", ModeChaCha20Poly1305.String())
+	assert.Equal(t, "EncryptionMode(7)", EncryptionMode(7).String())
+}
+
+func benchmarkMode(b *testing.B, mode EncryptionMode, keySize int) {
+	svc, err := NewServiceWithMode(randomKey(b, keySize), mode)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.Encrypt("52998224725"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncryptGCM(b *testing.B) {
+	benchmarkMode(b, ModeGCM, 32)
+}
+
+func BenchmarkEncryptChaCha20Poly1305(b *testing.B) {
+	benchmarkMode(b, ModeChaCha20Poly1305, 32)
+}
@@ -0,0 +1,100 @@
+package pseudonymization
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MetadataInitials is the Result.Metadata key holding the initials of a
+// pseudonymized name
+const MetadataInitials = "initials"
+
+// nameParticles are the lowercase connectives of Portuguese names, which do
+// not contribute an initial ("José da Silva" is J.S.)
+var nameParticles = map[string]bool{
+	"d": true, "da": true, "das": true, "de": true, "di": true,
+	"do": true, "dos": true, "du": true, "e": true,
+}
+
+// PseudonymizeName pseudonymizes a full name while keeping its initials
+// visible in Result.Metadata[MetadataInitials] (e.g. "J.S." for "José da
+// Silva"), for analytics that group or spot-check records by initials. The
+// name is hashed and encrypted with its whitespace collapsed to single
+// spaces, so "José  da Silva " and "José da Silva" get the same hash, and
+// RevertName returns the collapsed form.
+//
+// Initials are the upper-cased first letter of each word, accents included
+// ("Ângela" gives Â). Connectives such as "da", "de" and "dos" are skipped
+// unless the name consists only of them, and a single-word name has a single
+// initial. Note that initials reduce the anonymity set: combined with other
+// attributes they can help re-identify people in small groups.
+//
+// Parameters:
+// - fullName: The name to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Result containing pseudonymization artifacts and the initials
+// - error: ErrEmptyValue for an empty or blank name
+func (s *Service) PseudonymizeName(fullName, purpose, system string) (_ *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if err = s.checkLength(len(fullName)); err != nil {
+		return nil, err
+	}
+	if fullName, err = s.normalizeInput(fullName); err != nil {
+		return nil, err
+	}
+	words := strings.Fields(fullName)
+	if len(words) == 0 {
+		return nil, ErrEmptyValue
+	}
+	if err := s.checkNotPseudonym(fullName); err != nil {
+		return nil, err
+	}
+
+	result, err := s.newResult(context.Background(), strings.Join(words, " "), s.newPseudonym(), PseudonymizeOptions{Purpose: purpose, System: system})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Metadata = map[string]string{MetadataInitials: initials(words)}
+	return result, nil
+}
+
+// RevertName decrypts a name pseudonymized with PseudonymizeName. It is
+// equivalent to Revert and provided for symmetry.
+//
+// Parameters:
+// - encryptedValue: Base64-encoded encrypted value from PseudonymizeName
+//
+// Returns:
+// - The full name, with whitespace collapsed to single spaces
+// - error as for Revert
+func (s *Service) RevertName(encryptedValue string) (string, error) {
+	return s.Revert(encryptedValue)
+}
+
+// initials returns the dotted initials of the words of a name, skipping
+// connectives when there are other words
+func initials(words []string) string {
+	var b strings.Builder
+	for _, skipParticles := range []bool{true, false} {
+		for _, word := range words {
+			if skipParticles && nameParticles[strings.ToLower(word)] {
+				continue
+			}
+			first, _ := utf8.DecodeRuneInString(word)
+			b.WriteRune(unicode.ToUpper(first))
+			b.WriteByte('.')
+		}
+		if b.Len() > 0 {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizeName(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	testCases := []struct {
+		name     string
+		initials string
+		reverted string
+	}{
+		{"José da Silva", "J.S.", "José da Silva"},                                   // Connective skipped
+		{"  Maria   Aparecida dos  Santos ", "M.A.S.", "Maria Aparecida dos Santos"}, // Extra whitespace
+		{"ângela de Sá", "Â.S.", "ângela de Sá"},                                     // Accents, upper-cased
+		{"Madonna", "M.", "Madonna"},                                                 // Single word
+		{"Ana-Clara Souza", "A.S.", "Ana-Clara Souza"},                               // Hyphenated first name
+		{"Da Silva", "S.", "Da Silva"},                                               // Capitalized connective
+		{"de", "D.", "de"},                                                           // Only a connective
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := svc.PseudonymizeName(tc.name, "analytics", "crm")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.initials, result.Metadata[MetadataInitials])
+			assert.NotEqual(t, tc.reverted, result.Pseudonym)
+			assert.Equal(t, svc.Hash(tc.reverted), result.OriginalHash)
+
+			original, err := svc.RevertName(result.EncryptedValue)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.reverted, original)
+		})
+	}
+
+	_, err := svc.PseudonymizeName(" \t ", "analytics", "crm")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+}
@@ -0,0 +1,69 @@
+package pseudonymization
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalizer maps equivalent spellings of a value to a single canonical form
+// before it is hashed and encrypted (see WithNormalizer). Normalizers must be
+// idempotent: normalizing an already normalized value must not change it.
+type Normalizer func(value string) string
+
+// NormalizeCPF strips the punctuation and whitespace of a formatted CPF, so
+// "529.982.247-25" and "52998224725" are treated as the same value. Other
+// characters are kept, so non-CPF input is not silently merged with a CPF.
+func NormalizeCPF(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// NormalizeEmail trims surrounding whitespace and lowercases an email address.
+// The local part is lowercased too: strictly it is case-sensitive, but in
+// practice mail providers treat it case-insensitively.
+func NormalizeEmail(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// NormalizeWhitespace trims leading and trailing whitespace and collapses
+// every internal run of whitespace to a single space
+func NormalizeWhitespace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// NormalizeUnicode converts value to Unicode Normalization Form C, so that
+// precomposed and decomposed spellings of the same text, such as "José" with
+// a single "é" or with "e" followed by a combining acute accent, become the
+// same bytes. See WithUnicodeNormalization to apply it together with another
+// Normalizer.
+func NormalizeUnicode(value string) string {
+	return norm.NFC.String(value)
+}
+
+// normalize applies NFC normalization, if enabled, and then the configured
+// Normalizer, if any, to value
+func (s *Service) normalize(value string) string {
+	if s.unicodeNorm {
+		value = NormalizeUnicode(value)
+	}
+	if s.normalizer == nil {
+		return value
+	}
+	return s.normalizer(value)
+}
+
+// normalizeInput rejects value if it is not valid UTF-8 and normalizes it.
+// The error does not quote value, which may be sensitive.
+func (s *Service) normalizeInput(value string) (string, error) {
+	if !utf8.ValidString(value) {
+		return "", ErrInvalidUTF8
+	}
+	return s.normalize(value), nil
+}
@@ -0,0 +1,108 @@
+package pseudonymization
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		normalizer Normalizer
+		value      string
+		expected   string
+	}{
+		{"CPF formatted", NormalizeCPF, "529.982.247-25", "52998224725"},
+		{"CPF spaced", NormalizeCPF, " 529 982 247 25 ", "52998224725"},
+		{"CPF unformatted", NormalizeCPF, "52998224725", "52998224725"},
+		{"CPF keeps letters", NormalizeCPF, "abc-123", "abc123"},
+		{"email", NormalizeEmail, "  Maria.Silva@Example.COM\n", "maria.silva@example.com"},
+		{"whitespace", NormalizeWhitespace, "  Rua  das\tFlores,\n 123 ", "Rua das Flores, 123"},
+		{"empty", NormalizeWhitespace, "   ", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized := tc.normalizer(tc.value)
+			assert.Equal(t, tc.expected, normalized)
+			// Idempotent
+			assert.Equal(t, normalized, tc.normalizer(normalized))
+		})
+	}
+}
+
+func TestWithNormalizer(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)), WithNormalizer(NormalizeCPF))
+
+	formatted, err := svc.PseudonymizeDeterministic("529.982.247-25", "test", "test")
+	assert.NoError(t, err)
+	plain, err := svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	// Both spellings share hash and deterministic pseudonym
+	assert.Equal(t, plain.OriginalHash, formatted.OriginalHash)
+	assert.Equal(t, plain.Pseudonym, formatted.Pseudonym)
+	assert.True(t, svc.VerifyHash("529.982.247-25", plain.OriginalHash))
+	assert.Equal(t, plain.OriginalHash, svc.HashKeyed("529.982.247-25"))
+
+	// The normalized form is what gets encrypted
+	original, err := svc.Revert(formatted.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	_, err = NewService(randomKey(t, 32), WithNormalizer(NormalizeWhitespace)).Pseudonymize("   ", "test", "test")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+
+	// Without a normalizer the spellings differ
+	svc = NewService(randomKey(t, 32))
+	assert.NotEqual(t, svc.Hash("529.982.247-25"), svc.Hash("52998224725"))
+}
+
+func TestWithUnicodeNormalization(t *testing.T) {
+	composed := "Jos\u00e9 Concei\u00e7\u00e3o"
+	decomposed := "Jose\u0301 Conceic\u0327a\u0303o"
+	assert.Equal(t, composed, NormalizeUnicode(decomposed))
+
+	svc := NewService(randomKey(t, 32), WithUnicodeNormalization(), WithNormalizer(strings.ToUpper))
+	nfd, err := svc.Pseudonymize(decomposed, "test", "test")
+	assert.NoError(t, err)
+	nfc, err := svc.Pseudonymize(composed, "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, nfc.OriginalHash, nfd.OriginalHash)
+	assert.Equal(t, svc.Hash(composed), svc.Hash(decomposed))
+
+	// NFC runs before the custom normalizer, and is what gets encrypted
+	original, err := svc.Revert(nfd.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ToUpper(composed), original)
+
+	// Without it the spellings differ
+	plain := NewService(randomKey(t, 32))
+	assert.NotEqual(t, plain.Hash(composed), plain.Hash(decomposed))
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)), WithTokenVault(NewMemoryTokenVault()))
+	invalid := "Jos\xe9"
+
+	_, err := svc.Pseudonymize(invalid, "test", "test")
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+	assert.NotContains(t, err.Error(), "Jos")
+	_, err = svc.Anonymize(invalid, "test", "test")
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+	_, err = svc.PseudonymizeName(invalid, "test", "test")
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+	_, err = svc.Tokenize(invalid, "test", "test")
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+	_, err = svc.Encrypt(invalid)
+	assert.ErrorIs(t, err, ErrInvalidUTF8)
+
+	// Binary data goes through EncryptBytes
+	encrypted, err := svc.EncryptBytes([]byte(invalid))
+	assert.NoError(t, err)
+	decrypted, err := svc.DecryptBytes(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(invalid), decrypted)
+}
@@ -0,0 +1,50 @@
+package pseudonymization
+
+import "time"
+
+// Observer receives the outcome and latency of every pseudonymization and
+// revert, for metrics such as call counters, latency histograms and error
+// rates. It is called synchronously at the end of each operation, so
+// implementations must be cheap and safe for concurrent use.
+//
+// The package has no dependency on any metrics library; see the README for
+// an Observer backed by Prometheus.
+type Observer interface {
+	// ObservePseudonymize is called once per pseudonymized value, including
+	// each value of PseudonymizeBatch and the values of Anonymize,
+	// PseudonymizeLight, Tokenize and Repseudonymize; err is nil on success
+	ObservePseudonymize(duration time.Duration, err error)
+
+	// ObserveRevert is called once per reverted value, including each value
+	// of RevertBatch and of Detokenize; err is nil on success
+	ObserveRevert(duration time.Duration, err error)
+}
+
+// NoopObserver discards every observation. It is the default Observer.
+type NoopObserver struct{}
+
+// ObservePseudonymize does nothing
+func (NoopObserver) ObservePseudonymize(time.Duration, error) {}
+
+// ObserveRevert does nothing
+func (NoopObserver) ObserveRevert(time.Duration, error) {}
+
+// observePseudonymize reports a pseudonymization started at start; it is
+// meant to be deferred with a pointer to the caller's named error result
+func (s *Service) observePseudonymize(start time.Time, err *error) {
+	duration := time.Since(start)
+	if s.stats != nil {
+		s.stats.pseudonymize.record(duration, *err)
+	}
+	s.observer.ObservePseudonymize(duration, *err)
+}
+
+// observeRevert reports a revert started at start; it is meant to be
+// deferred with a pointer to the caller's named error result
+func (s *Service) observeRevert(start time.Time, err *error) {
+	duration := time.Since(start)
+	if s.stats != nil {
+		s.stats.revert.record(duration, *err)
+	}
+	s.observer.ObserveRevert(duration, *err)
+}
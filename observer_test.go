@@ -0,0 +1,93 @@
+package pseudonymization
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingObserver keeps every observed error in memory
+type recordingObserver struct {
+	mu           sync.Mutex
+	pseudonymize []error
+	revert       []error
+}
+
+func (o *recordingObserver) ObservePseudonymize(duration time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pseudonymize = append(o.pseudonymize, err)
+}
+
+func (o *recordingObserver) ObserveRevert(duration time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.revert = append(o.revert, err)
+}
+
+func TestObserver(t *testing.T) {
+	observer := &recordingObserver{}
+	svc := NewService(randomKey(t, 32), WithObserver(observer))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.Pseudonymize("", "test", "test")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+	_, err = svc.PseudonymizeEmail("user@example.com", "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.PseudonymizeCPFFormatPreserving("529.982.247-25", "test", "test")
+	assert.NoError(t, err)
+	_, _ = svc.PseudonymizeBatch([]string{"a", ""}, "test", "test")
+
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	_, err = svc.Revert("invalid")
+	assert.Error(t, err)
+	_, _ = svc.RevertBatch([]string{result.EncryptedValue, ""})
+
+	// Errors are passed through so they can be counted
+	assert.Len(t, observer.pseudonymize, 6)
+	assert.NoError(t, observer.pseudonymize[0])
+	assert.ErrorIs(t, observer.pseudonymize[1], ErrEmptyValue)
+	assert.NoError(t, observer.pseudonymize[4])
+	assert.ErrorIs(t, observer.pseudonymize[5], ErrEmptyValue)
+
+	assert.Len(t, observer.revert, 4)
+	assert.NoError(t, observer.revert[0])
+	assert.Error(t, observer.revert[1])
+	assert.NoError(t, observer.revert[2])
+	assert.ErrorIs(t, observer.revert[3], ErrNotReversible)
+
+	// Every other way of pseudonymizing or reverting a value is observed too
+	observer = &recordingObserver{}
+	svc = NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)), WithTokenVault(NewMemoryTokenVault()), WithObserver(observer))
+	_, err = svc.PseudonymizePhone("(11) 98765-4321", "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.Anonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, _, err = svc.PseudonymizeLight("52998224725", "test", "test")
+	assert.NoError(t, err)
+	token, err := svc.Tokenize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	result, err = svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.Repseudonymize(result.EncryptedValue, "11144477735", "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.Repseudonymize("invalid", "11144477735", "test", "test")
+	assert.Error(t, err)
+	_, err = svc.Detokenize(token)
+	assert.NoError(t, err)
+
+	// Repseudonymize is reported once, whether or not it fails
+	assert.Len(t, observer.pseudonymize, 7)
+	assert.Error(t, observer.pseudonymize[6])
+	assert.Len(t, observer.revert, 1)
+	assert.NoError(t, observer.revert[0])
+
+	// A nil observer keeps the no-op default
+	svc = NewService(randomKey(t, 32), WithObserver(nil))
+	_, err = svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,345 @@
+package pseudonymization
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Option configures optional behaviour of a Service
+type Option func(*Service)
+
+// WithHMACKey sets the secret key used for keyed hashing, an HMAC using the
+// configured hash algorithm (see WithHashAlgorithm). When set, Pseudonymize
+// populates Result.OriginalHash with HashKeyed instead of the plain hash.
+//
+// The HMAC key must be different from the encryption key and at least 16 bytes long.
+func WithHMACKey(key []byte) Option {
+	return func(s *Service) {
+		s.ring.hmacKey = append(make([]byte, 0, len(key)), key...)
+	}
+}
+
+// WithHashAlgorithm selects the hash function used for Result.OriginalHash,
+// Hash, HashKeyed, HashWithSalt and their Verify counterparts (inside HMAC
+// when an HMAC key is configured). Defaults to HashSHA256. The length of
+// OriginalHash follows the algorithm, so switching algorithms breaks
+// comparisons with hashes stored before the switch.
+func WithHashAlgorithm(algorithm HashAlgorithm) Option {
+	return func(s *Service) {
+		s.ring.hashAlg = algorithm
+	}
+}
+
+// WithHashEncoding selects how hashes are written: Result.OriginalHash and
+// the output of Hash, HashKeyed, HashWithSalt and Rehash. VerifyHash and
+// VerifySaltedHash decode expected hashes with the same encoding. Defaults to
+// HashEncodingHex. Switching encodings changes the stored representation of
+// every new hash, so hashes stored before the switch must be re-encoded (or
+// recomputed with Rehash) before they can be compared with new ones.
+func WithHashEncoding(encoding HashEncoding) Option {
+	return func(s *Service) {
+		s.hashEncoding = encoding
+	}
+}
+
+// WithMaxValueLength sets the largest value, in bytes, that the service
+// pseudonymizes, anonymizes or encrypts; larger values fail with
+// ErrValueTooLarge before any hashing or encryption work is done. Defaults
+// to DefaultMaxValueLength (1 MiB), which guards services exposed to
+// untrusted input against memory exhaustion. Raise it for services that
+// encrypt large blobs, or use EncryptStream, which is not limited; zero
+// disables the limit.
+func WithMaxValueLength(limit int) Option {
+	return func(s *Service) {
+		s.maxValueLength = limit
+	}
+}
+
+// WithNamespace sets the UUID namespace PseudonymizeDeterministic derives
+// pseudonyms from. Services sharing a namespace (and HMAC key, if any) produce
+// the same pseudonym for the same value; use distinct namespaces to keep
+// pseudonyms from different contexts unlinkable.
+func WithNamespace(namespace uuid.UUID) Option {
+	return func(s *Service) {
+		s.namespace = namespace
+	}
+}
+
+// WithUUIDPseudonyms makes PseudonymizeWith reject pseudonyms that are not
+// UUIDs, for deployments whose downstream systems expect the UUID format
+func WithUUIDPseudonyms() Option {
+	return func(s *Service) {
+		s.uuidOnly = true
+	}
+}
+
+// WithPurposeBinding binds the purpose and system passed to Pseudonymize to
+// the ciphertext as AES-GCM additional authenticated data. Values encrypted
+// this way can only be reverted with RevertWithContext under the same purpose
+// and system; Revert only succeeds for values pseudonymized with an empty
+// purpose and system.
+func WithPurposeBinding() Option {
+	return func(s *Service) {
+		s.bindPurpose = true
+	}
+}
+
+// WithDeterministicEncryption makes encryption deterministic: the AES-GCM (or
+// ChaCha20-Poly1305) nonce is derived from an HMAC of the value and its
+// additional data instead of drawn at random, so pseudonymizing the same
+// value twice gives the same EncryptedValue and encrypted columns can be
+// deduplicated or used as content addresses. The nonce key is derived from
+// the active encryption key. ModeSIV is deterministic already and ignores
+// this option.
+//
+// WARNING: deterministic ciphertexts leak equality. Anyone who can read the
+// encrypted column learns which records hold the same value, and can count
+// how often each value occurs, which for low-entropy data such as CPFs or
+// birth dates can be enough to re-identify people by frequency analysis.
+// This is the same tradeoff as ModeSIV; enable it only where that leak is
+// acceptable. Values are only deduplicated when the additional data matches
+// too, so Results with a TTL (whose expiry is authenticated) or bound to a
+// different purpose and system never share a ciphertext. Streams
+// (EncryptStream) keep random nonces.
+func WithDeterministicEncryption() Option {
+	return func(s *Service) {
+		if s.ring.mode != ModeSIV {
+			s.ring.nonceKey = deriveNonceKey(s.ring.keys[s.ring.active])
+		}
+	}
+}
+
+// WithEnvelopeFormat makes the service write new ciphertexts in the
+// self-describing envelope format, prefixed with a format version and an
+// algorithm ID (see MigrateCiphertext), so that future format changes can be
+// told apart from today's values. Every Service reads both formats whether
+// or not this option is set; enable it once all readers run a release that
+// understands envelopes.
+func WithEnvelopeFormat() Option {
+	return func(s *Service) {
+		s.ring.envelope = true
+	}
+}
+
+// WithLegacyKeyVersion selects the key used to decrypt ciphertexts that carry
+// no key version header, i.e. values encrypted before the service switched to
+// NewServiceWithKeyring. Defaults to the lowest version in the keyring.
+func WithLegacyKeyVersion(version int) Option {
+	return func(s *Service) {
+		s.ring.legacy = version
+	}
+}
+
+// WithNonceLimit sets how many encryptions the service performs under its
+// active key before every further encryption fails with
+// ErrNonceLimitApproaching, signaling that the key must be rotated. Defaults
+// to DefaultNonceLimit; zero disables the limit. Each stream chunk counts as
+// one encryption, and AES-SIV (ModeSIV) is never limited.
+//
+// The count is kept in memory, per Service: it starts at zero on every
+// restart and is not shared between instances using the same key. Services
+// spread over many processes should set a proportionally lower limit, or
+// rotate keys on a schedule that keeps the total below DefaultNonceLimit.
+func WithNonceLimit(limit uint64) Option {
+	return func(s *Service) {
+		s.ring.nonceLimit = limit
+	}
+}
+
+// WithAuditLogger sends an AuditEvent to logger for every successful
+// pseudonymization and re-identification. Revert events are the evidence
+// trail for who re-identified data and why, so production services should
+// always configure a durable logger.
+func WithAuditLogger(logger AuditLogger) Option {
+	return func(s *Service) {
+		if logger == nil {
+			logger = NoopAuditLogger{}
+		}
+		s.auditLogger = logger
+	}
+}
+
+// WithObserver reports the latency and outcome of every pseudonymization and
+// revert to observer, for metrics. A nil observer keeps the default
+// NoopObserver.
+func WithObserver(observer Observer) Option {
+	return func(s *Service) {
+		if observer == nil {
+			observer = NoopObserver{}
+		}
+		s.observer = observer
+	}
+}
+
+// WithStats enables the counters and rolling latency percentiles returned by
+// Service.Stats, computed over the last window calls of each operation; a
+// window of zero or less uses DefaultStatsWindow. It suits quick operational
+// insight without a metrics stack; prefer WithObserver where one exists.
+func WithStats(window int) Option {
+	return func(s *Service) {
+		if window <= 0 {
+			window = DefaultStatsWindow
+		}
+		s.stats = &serviceStats{
+			pseudonymize: newLatencyTracker(window),
+			revert:       newLatencyTracker(window),
+		}
+	}
+}
+
+// WithRateLimiter makes every re-identification (Revert and its variants,
+// RevertBatch, RevertDetailed, RevertAny and Detokenize) consult limiter
+// before decrypting, failing with ErrRateLimited once the quota is spent.
+// Failed attempts count against the quota too, which slows down an insider
+// trying values at scale. Decrypt, ReEncrypt and Rehash are not limited:
+// they are not audited as re-identification and serve key management. A nil
+// limiter disables the limit, the default.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(s *Service) {
+		s.rateLimiter = limiter
+	}
+}
+
+// WithClock sets the time source used for Result.Timestamp and audit event
+// timestamps. Defaults to time.Now; tests and replay tooling can freeze it to
+// obtain reproducible results. A nil clock keeps the default.
+func WithClock(clock func() time.Time) Option {
+	return func(s *Service) {
+		if clock == nil {
+			clock = time.Now
+		}
+		s.clock = clock
+	}
+}
+
+// WithTokenVault sets the vault used by Tokenize and Detokenize to persist
+// encrypted values behind their tokens
+func WithTokenVault(vault TokenVault) Option {
+	return func(s *Service) {
+		s.tokenVault = vault
+	}
+}
+
+// WithStrictMode makes Pseudonymize and its variants (including
+// PseudonymizeEmail, PseudonymizeName, PseudonymizeLight, Anonymize and
+// Tokenize), PseudonymizeBatch and PseudonymizeAll reject values that parse
+// as a UUID with ErrAlreadyPseudonymized. Pseudonyms are UUIDs, so this catches pipelines
+// that pseudonymize the same column twice, which would otherwise silently
+// replace the pseudonym with a new one and lose the link to the record. Do
+// not enable it for fields whose legitimate values are UUIDs.
+func WithStrictMode() Option {
+	return func(s *Service) {
+		s.strict = true
+	}
+}
+
+// WithPIIDetection fills Result.PIIType with the kind of each pseudonymized
+// value (see DetectPIIType), e.g. for governance reports counting how many
+// CPFs and email addresses were pseudonymized. Detection runs after
+// normalization and costs a few regular expression and check digit passes
+// per value, so it is off by default.
+func WithPIIDetection() Option {
+	return func(s *Service) {
+		s.detectPII = true
+	}
+}
+
+// WithPseudonymGenerator sets the generator of random pseudonyms, e.g.
+// UUIDv7Generator or ULIDGenerator for values that sort by creation time and
+// keep database index inserts local. Those leak when each record was
+// pseudonymized to anyone who sees the pseudonym; keep the default
+// UUIDv4Generator when that matters. A nil generator keeps the default.
+func WithPseudonymGenerator(generator PseudonymGenerator) Option {
+	return func(s *Service) {
+		s.generator = generator
+	}
+}
+
+// WithCollisionChecker passes every deterministic pseudonym, with the hash of
+// its value, to checker before it is returned. If checker reports that the
+// pseudonym already belongs to another value, the operation fails with its
+// error (wrapping ErrPseudonymCollision) instead of merging two people under
+// one pseudonym. Random pseudonyms are not checked.
+func WithCollisionChecker(checker CollisionChecker) Option {
+	return func(s *Service) {
+		s.collisionChecker = checker
+	}
+}
+
+// WithNormalizer applies normalizer to every value before it is hashed and
+// encrypted, so differently formatted spellings of the same value (e.g.
+// "529.982.247-25" and "52998224725") share a hash and a deterministic
+// pseudonym. Hash, HashKeyed, VerifyHash and the salted hash methods
+// normalize their input too, so lookups match stored hashes.
+//
+// The normalized value is what gets encrypted: Revert returns the normalized
+// form, not the original spelling. Values that normalize to the empty string
+// are rejected with ErrEmptyValue. PseudonymizeCPFFormatPreserving always
+// reduces the CPF to its digits and ignores the normalizer.
+func WithNormalizer(normalizer Normalizer) Option {
+	return func(s *Service) {
+		s.normalizer = normalizer
+	}
+}
+
+// WithUnicodeNormalization converts every value to Unicode NFC (see
+// NormalizeUnicode) before it is hashed and encrypted, and before the
+// Normalizer of WithNormalizer runs, so visually identical names typed on
+// different systems share a hash and a deterministic pseudonym. As with
+// WithNormalizer, Revert returns the normalized form.
+func WithUnicodeNormalization() Option {
+	return func(s *Service) {
+		s.unicodeNorm = true
+	}
+}
+
+// WithCiphertextEncoding sets the base64 encoding of Result.EncryptedValue and
+// of the other encrypted strings the service returns. Defaults to
+// base64.StdEncoding, whose "+" and "/" must be escaped in URLs and file
+// names; base64.URLEncoding or base64.RawURLEncoding avoid that. Decryption
+// accepts every base64 variant regardless of this setting, so switching
+// encodings keeps previously stored values revertible. A nil encoding keeps
+// the default.
+func WithCiphertextEncoding(encoding *base64.Encoding) Option {
+	return func(s *Service) {
+		if encoding == nil {
+			encoding = base64.StdEncoding
+		}
+		s.encoding = encoding
+	}
+}
+
+// WithSaltedHash makes Pseudonymize fill Result.OriginalHash with a salted
+// hash (see HashWithSalt) and store its salt in Result.HashSalt. Salting
+// defeats precomputed tables for low-entropy values such as CPFs, but the
+// hashes can no longer be compared across records, so do not enable it when
+// OriginalHash is used for equality joins or lookups.
+func WithSaltedHash() Option {
+	return func(s *Service) {
+		s.saltedHash = true
+	}
+}
+
+// WithRandomSource replaces crypto/rand.Reader as the source of the service's
+// nonces, hash salts, tokens, random UUID v4 pseudonyms and synthetic CPF
+// pseudonyms, so tests can supply a deterministic reader and assert exact
+// ciphertexts. A nil source
+// keeps the default. Generators set with WithPseudonymGenerator keep their
+// own source.
+//
+// Never use it in production: a predictable source yields predictable
+// pseudonyms and tokens, and a repeated AES-GCM nonce exposes the plaintexts
+// and the authentication key. The reader must be safe for concurrent use if
+// the service is.
+func WithRandomSource(random io.Reader) Option {
+	return func(s *Service) {
+		if random == nil {
+			random = rand.Reader
+		}
+		s.ring.random = random
+	}
+}
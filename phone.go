@@ -0,0 +1,56 @@
+package pseudonymization
+
+import (
+	"context"
+	"time"
+
+	"github.com/raywall/pseudonymization-lgpd-tools/utils"
+)
+
+// Result.Metadata keys set by PseudonymizePhone
+const (
+	MetadataCountryCode = "country_code"
+	MetadataAreaCode    = "area_code"
+)
+
+// PseudonymizePhone pseudonymizes a Brazilian phone number while keeping its
+// country code and area code (DDD) visible in Result.Metadata for regional
+// analytics. The pseudonym stands in for the subscriber digits; the full
+// number is normalized to E.164, hashed and encrypted, so Revert returns it in
+// E.164 form (e.g. +5511987654321).
+//
+// Parameters:
+// - phone: The phone number to pseudonymize (mobile or landline, formatted or not)
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Result containing pseudonymization artifacts, country code and DDD
+// - error wrapping ErrInvalidPhone if phone is not a valid Brazilian number
+func (s *Service) PseudonymizePhone(phone, purpose, system string) (_ *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if err = s.checkLength(len(phone)); err != nil {
+		return nil, err
+	}
+	if len(phone) == 0 {
+		return nil, ErrEmptyValue
+	}
+
+	normalized, err := utils.NormalizePhoneBR(phone)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.newResult(context.Background(), normalized, s.newPseudonym(), PseudonymizeOptions{Purpose: purpose, System: system})
+	if err != nil {
+		return nil, err
+	}
+
+	// E.164: +55 DD NNNNNNNNN
+	result.Metadata = map[string]string{
+		MetadataCountryCode: normalized[1:3],
+		MetadataAreaCode:    normalized[3:5],
+	}
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizePhone(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.PseudonymizePhone("+55 (11) 98765-4321", "analytics", "crm")
+	assert.NoError(t, err)
+	assert.Equal(t, "55", result.Metadata[MetadataCountryCode])
+	assert.Equal(t, "11", result.Metadata[MetadataAreaCode])
+
+	original, err := svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "+5511987654321", original)
+
+	// Formatting does not change the hash
+	landline, err := svc.PseudonymizePhone("(21) 3265-4321", "analytics", "crm")
+	assert.NoError(t, err)
+	assert.Equal(t, "21", landline.Metadata[MetadataAreaCode])
+	assert.Equal(t, svc.Hash("+552132654321"), landline.OriginalHash)
+
+	_, err = svc.PseudonymizePhone("(11) 1234", "analytics", "crm")
+	assert.ErrorIs(t, err, ErrInvalidPhone)
+
+	_, err = svc.PseudonymizePhone("", "analytics", "crm")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+}
@@ -0,0 +1,62 @@
+package pseudonymization
+
+import (
+	"regexp"
+
+	"github.com/raywall/pseudonymization-lgpd-tools/utils"
+)
+
+// PIIType names the kind of personal data a value holds, as detected by
+// DetectPIIType and stored in Result.PIIType
+type PIIType string
+
+// PII types reported by DetectPIIType
+const (
+	PIITypeCPF     PIIType = "cpf"
+	PIITypeCNPJ    PIIType = "cnpj"
+	PIITypeCNH     PIIType = "cnh"
+	PIITypeEmail   PIIType = "email"
+	PIITypePhone   PIIType = "phone"
+	PIITypeUnknown PIIType = "unknown"
+)
+
+var (
+	// emailPattern matches a single @ between a non-empty local part and a
+	// dotted domain, without whitespace
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+	// phonePattern matches digits with the usual phone punctuation, so that
+	// NormalizePhoneBR only sees values that look like a phone number
+	phonePattern = regexp.MustCompile(`^\+?[0-9 ().-]+$`)
+)
+
+// DetectPIIType classifies value as a Brazilian document (by length and
+// check digits, see utils.DetectDocumentType), an email address or a
+// Brazilian phone number. Documents are tried first: an 11-digit mobile
+// number that happens to pass the CPF check digits is reported as a CPF.
+//
+// Parameters:
+// - value: The value to classify
+//
+// Returns:
+// - PIIType of value, or PIITypeUnknown if no type matches
+func DetectPIIType(value string) PIIType {
+	switch documentType, _ := utils.DetectDocumentType(value); documentType {
+	case utils.DocumentCPF:
+		return PIITypeCPF
+	case utils.DocumentCNPJ:
+		return PIITypeCNPJ
+	case utils.DocumentCNH:
+		return PIITypeCNH
+	}
+
+	if emailPattern.MatchString(value) {
+		return PIITypeEmail
+	}
+	if phonePattern.MatchString(value) {
+		if _, err := utils.NormalizePhoneBR(value); err == nil {
+			return PIITypePhone
+		}
+	}
+	return PIITypeUnknown
+}
@@ -0,0 +1,55 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectPIIType(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected PIIType
+	}{
+		{"529.982.247-25", PIITypeCPF},
+		{"52998224725", PIITypeCPF},
+		{"11.222.333/0001-81", PIITypeCNPJ},
+		{"maria.silva@example.com.br", PIITypeEmail},
+		{"+55 11 98765-4321", PIITypePhone},
+		{"(11) 3265-4321", PIITypePhone},
+		{"Maria da Silva", PIITypeUnknown},
+		{"maria@localhost", PIITypeUnknown},    // No dotted domain
+		{"tel: 11 98765-4321", PIITypeUnknown}, // Not only phone characters
+		{"529.982.247-26", PIITypeUnknown},     // Wrong check digit
+		{"", PIITypeUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DetectPIIType(tc.value))
+		})
+	}
+}
+
+func TestWithPIIDetection(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithPIIDetection())
+
+	results, err := svc.PseudonymizeBatch([]string{"529.982.247-25", "maria@example.com", "Maria"}, "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, PIITypeCPF, results[0].PIIType)
+	assert.Equal(t, PIITypeEmail, results[1].PIIType)
+	assert.Equal(t, PIITypeUnknown, results[2].PIIType)
+
+	data, err := results[0].ToJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"pii_type":"cpf"`)
+	assert.Contains(t, results[0].String(), "pii_type=cpf")
+
+	// Off by default
+	result, err := NewService(randomKey(t, 32)).Pseudonymize("529.982.247-25", "test", "test")
+	assert.NoError(t, err)
+	assert.Empty(t, result.PIIType)
+	data, err = result.ToJSON()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "pii_type")
+}
@@ -1,30 +1,154 @@
 package pseudonymization
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"crypto/sha256"
+	"bytes"
+	"context"
+	"crypto/hmac"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
-	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
 // Result represents the output of a pseudonymization operation
 type Result struct {
-	OriginalHash   string `json:"original_hash_value"`      // SHA-256 hash of original value (hex encoded)
-	Pseudonym      string `json:"client_id"`                // Generated UUID v4 pseudonym
+	OriginalHash   string `json:"original_hash_value"`      // Hash of original value (hex encoded by default), SHA-256 by default
+	Pseudonym      string `json:"client_id"`                // Generated pseudonym, a UUID v4 by default
 	EncryptedValue string `json:"encrypted_original_value"` // AES-GCM encrypted original value (base64 encoded)
 	Timestamp      int64  `json:"anonymization_at"`         // Unix timestamp of operation
+
+	// ExpiresAt is the Unix time after which Revert refuses to decrypt the
+	// value, or 0 if it never expires; see PseudonymizeWithTTL
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+
+	// HashSalt is the hex-encoded salt of OriginalHash when the service was
+	// created with WithSaltedHash; verify with VerifySaltedHash
+	HashSalt string `json:"hash_salt,omitempty"`
+
+	// Metadata holds non-sensitive attributes kept in clear text, such as the
+	// domain of a pseudonymized email address
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// PIIType is the detected kind of the original value when the service was
+	// created with WithPIIDetection, and empty otherwise
+	PIIType PIIType `json:"pii_type,omitempty"`
+}
+
+// PseudonymizeOptions configures a single PseudonymizeWithOptions call. The
+// zero value behaves like Pseudonymize with an empty purpose and system.
+type PseudonymizeOptions struct {
+	Purpose string // Reason for pseudonymization (for audit trails)
+	System  string // Originating system (for audit trails)
+
+	// Deterministic derives the pseudonym from the value, as
+	// PseudonymizeDeterministic does, instead of generating a random UUID v4
+	Deterministic bool
+
+	// Pseudonym is used as Result.Pseudonym instead of a generated one, as in
+	// PseudonymizeWith. It cannot be combined with Deterministic.
+	Pseudonym string
+
+	// Clock overrides the source of Result.Timestamp for this call; nil uses
+	// the service clock (see WithClock)
+	Clock func() time.Time
+
+	// OmitTimestamp leaves Result.Timestamp at zero, so that the Result does
+	// not depend on when it was produced. The clock is still used for TTL.
+	//
+	// The whole Result is reproducible (e.g. for golden-file tests) when
+	// OmitTimestamp is combined with Deterministic (or a fixed Pseudonym),
+	// deterministic encryption (WithDeterministicEncryption or ModeSIV), no
+	// TTL and no WithSaltedHash, since each of these otherwise adds a random
+	// or time-dependent field.
+	OmitTimestamp bool
+
+	// AdditionalData is authenticated together with the ciphertext without
+	// being stored in it. The same bytes must be passed to RevertWithOptions.
+	AdditionalData []byte
+
+	// TTL bounds the retention of the value: Result.ExpiresAt is set to the
+	// operation time plus TTL and authenticated with the ciphertext. Zero
+	// means no expiry; negative values are rejected with ErrInvalidTTL.
+	TTL time.Duration
 }
 
+// RevertOptions carries the context a value was pseudonymized under, as
+// required by RevertWithOptions
+type RevertOptions struct {
+	Purpose        string // Purpose the value was pseudonymized for
+	System         string // System the value was pseudonymized by
+	AdditionalData []byte // AdditionalData given to PseudonymizeWithOptions
+	ExpiresAt      int64  // Result.ExpiresAt of a value pseudonymized with a TTL
+
+	// IgnoreExpiry reverts a value even after ExpiresAt, for legal holds and
+	// other obligations that override the retention period. Such reverts are
+	// audited like any other.
+	IgnoreExpiry bool
+}
+
+// minHMACKeyLength is the minimum accepted size of the HMAC key
+const minHMACKeyLength = 16
+
+// saltSize is the size of the random salt generated by HashWithSalt
+const saltSize = 16
+
+// DefaultMaxValueLength is the largest value, in bytes, a Service accepts
+// unless configured otherwise with WithMaxValueLength
+const DefaultMaxValueLength = 1 << 20
+
+// DefaultNamespace is the UUID namespace used by PseudonymizeDeterministic
+// when no namespace is configured with WithNamespace
+var DefaultNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/raywall/pseudonymization-lgpd-tools"))
+
 // Service provides pseudonymization methods
+//
+// A Service is safe for concurrent use by multiple goroutines: a single
+// instance can be shared by a whole application. Its configuration is fixed
+// once the constructor returns, every encryption draws its own random nonce,
+// and the key material is guarded by a lock so that Close can run alongside
+// other calls. Values supplied through options (AuditLogger, Observer,
+// TokenVault, the WithClock function) are called concurrently and must be
+// safe for concurrent use too. Any mutable state added to Service must be
+// synchronized to keep this guarantee.
 type Service struct {
-	encryptionKey []byte
+	namespace   uuid.UUID
+	uuidOnly    bool
+	strict      bool
+	detectPII   bool
+	generator   PseudonymGenerator
+	bindPurpose bool
+	saltedHash  bool
+	clock       func() time.Time
+	normalizer  Normalizer
+	unicodeNorm bool
+	encoding    *base64.Encoding
+	auditLogger AuditLogger
+	observer    Observer
+	stats       *serviceStats
+	tokenVault  TokenVault
+	rateLimiter RateLimiter
+	tenant      string
+
+	// collisionChecker, if set, sees every deterministic pseudonym
+	collisionChecker CollisionChecker
+
+	// maxValueLength bounds the size of values, or 0 for no limit
+	maxValueLength int
+
+	// hashEncoding is the string encoding of the hashes the service returns
+	hashEncoding HashEncoding
+
+	// ring holds the encryption keys and their ciphers, built once;
+	// the ciphers' Seal and Open methods are safe for concurrent use
+	ring *keyring
 }
 
 // NewService creates a new pseudonymization service instance
@@ -32,10 +156,96 @@ type Service struct {
 // Parameters:
 //   - encryptionKey: 32-byte key for AES-256 encryption
 //     In production, should come from secure key management
-func NewService(encryptionKey []byte) *Service {
-	return &Service{
-		encryptionKey: encryptionKey,
+//   - opts: optional settings such as WithHMACKey
+//
+// NewService panics if the key is not a valid AES key, or is weak (all zeros
+// or a repeated pattern, see ErrWeakKey). Use NewServiceWithError to handle
+// an invalid key as an error instead.
+func NewService(encryptionKey []byte, opts ...Option) *Service {
+	svc, err := NewServiceWithError(encryptionKey, opts...)
+	if err != nil {
+		panic("pseudonymization: " + err.Error())
+	}
+	return svc
+}
+
+// NewServiceWithError creates a new pseudonymization service instance,
+// validating the encryption key up front
+//
+// Parameters:
+//   - encryptionKey: 16, 24 or 32-byte key for AES-128, AES-192 or AES-256
+//   - opts: optional settings such as WithHMACKey
+//
+// Returns:
+//   - Service ready for use
+//   - error wrapping ErrInvalidKeyLength if the key has an unsupported size,
+//     ErrWeakKey if it is all zeros or a repeated pattern, or
+//     ErrInvalidHMACKey if a configured HMAC key is too short
+func NewServiceWithError(encryptionKey []byte, opts ...Option) (*Service, error) {
+	if err := validateKeyLength(encryptionKey); err != nil {
+		return nil, err
+	}
+
+	ring, err := newKeyring(map[int][]byte{0: encryptionKey}, 0, false, ModeGCM)
+	if err != nil {
+		return nil, err
 	}
+	return newService(ring, opts)
+}
+
+// NewServiceWithKeyring creates a pseudonymization service that supports key
+// rotation. Every ciphertext it produces starts with a one-byte header naming
+// the key version used, so Revert can pick the matching key after the active
+// key changes. Ciphertexts without a version header (produced by a service
+// created with NewService) are decrypted with the legacy key: by default the
+// lowest version in keys, configurable with WithLegacyKeyVersion.
+//
+// Parameters:
+//   - keys: encryption keys by version (0-255); each must be 16, 24 or 32 bytes
+//   - activeVersion: version of the key used for new encryptions
+//   - opts: optional settings such as WithHMACKey or WithLegacyKeyVersion
+//
+// Returns:
+//   - Service ready for use
+//   - error if a key is invalid or activeVersion is not in keys
+func NewServiceWithKeyring(keys map[int][]byte, activeVersion int, opts ...Option) (*Service, error) {
+	ring, err := newKeyring(keys, activeVersion, true, ModeGCM)
+	if err != nil {
+		return nil, err
+	}
+	return newService(ring, opts)
+}
+
+// newService applies opts to a service backed by ring and validates the result
+func newService(ring *keyring, opts []Option) (*Service, error) {
+	svc := &Service{
+		namespace:   DefaultNamespace,
+		clock:       time.Now,
+		encoding:    base64.StdEncoding,
+		auditLogger: NoopAuditLogger{},
+		observer:    NoopObserver{},
+		ring:        ring,
+
+		maxValueLength: DefaultMaxValueLength,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	if ring.hashAlg.newHash() == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedHashAlgorithm, ring.hashAlg)
+	}
+	if !svc.hashEncoding.valid() {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedHashEncoding, svc.hashEncoding)
+	}
+	if ring.keyed() && len(ring.hmacKey) < minHMACKeyLength {
+		return nil, fmt.Errorf("%w: got %d bytes", ErrInvalidHMACKey, len(ring.hmacKey))
+	}
+	if _, ok := ring.keys[ring.legacy]; !ok {
+		return nil, fmt.Errorf("%w: legacy version %d", ErrUnknownKeyVersion, ring.legacy)
+	}
+
+	return svc, nil
 }
 
 // Pseudonymize processes a sensitive value and returns pseudonymization artifacts
@@ -49,29 +259,392 @@ func NewService(encryptionKey []byte) *Service {
 // - Result containing pseudonymization artifacts
 // - error if operation fails
 func (s *Service) Pseudonymize(value, purpose, system string) (*Result, error) {
+	return s.PseudonymizeContext(context.Background(), value, purpose, system)
+}
+
+// PseudonymizeContext is like Pseudonymize but honours cancellation and
+// deadlines of ctx, which is also passed on to audit hooks
+//
+// Parameters:
+// - ctx: Request-scoped context; no work is done if it is already done
+// - value: The sensitive value to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Result containing pseudonymization artifacts
+// - error if operation fails or ctx is done
+func (s *Service) PseudonymizeContext(ctx context.Context, value, purpose, system string) (*Result, error) {
+	return s.pseudonymize(ctx, value, PseudonymizeOptions{Purpose: purpose, System: system})
+}
+
+// PseudonymizeDeterministic works like Pseudonymize, but derives a stable
+// UUID v5 pseudonym from the value and the service namespace (see
+// WithNamespace), so identical inputs always map to the same pseudonym and
+// datasets can be joined on it. The encrypted value still uses a random nonce.
+//
+// Privacy tradeoff: a random UUID v4 reveals nothing about the value, while a
+// deterministic pseudonym reveals which records share the same value. If no
+// HMAC key is configured, anyone who knows the namespace can also confirm a
+// guessed value by recomputing its pseudonym, so the namespace must be kept
+// secret. With WithHMACKey the pseudonym is derived from the keyed hash and
+// cannot be recomputed without the HMAC key.
+//
+// Parameters:
+// - value: The sensitive value to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Result containing pseudonymization artifacts
+// - error if operation fails
+func (s *Service) PseudonymizeDeterministic(value, purpose, system string) (*Result, error) {
+	return s.pseudonymize(context.Background(), value, PseudonymizeOptions{
+		Purpose:       purpose,
+		System:        system,
+		Deterministic: true,
+	})
+}
+
+// PseudonymizeWithOptions is the general form of Pseudonymize and
+// PseudonymizeDeterministic. New knobs are added to PseudonymizeOptions
+// rather than as new methods, and the zero value of every field keeps the
+// behaviour of Pseudonymize.
+//
+// Parameters:
+// - value: The sensitive value to pseudonymize
+// - opts: Purpose, system and optional behaviour for this call
+//
+// Returns:
+// - Result containing pseudonymization artifacts
+// - error if operation fails
+func (s *Service) PseudonymizeWithOptions(value string, opts PseudonymizeOptions) (*Result, error) {
+	return s.pseudonymize(context.Background(), value, opts)
+}
+
+// PseudonymizeWithTTL works like Pseudonymize, but bounds the retention of
+// the value: the Result records ExpiresAt, and once it has passed Revert
+// refuses to decrypt the value with ErrExpired unless IgnoreExpiry is set.
+// The expiry is authenticated with the ciphertext, so editing ExpiresAt in
+// storage makes decryption fail instead of extending the retention period.
+//
+// Because of that binding, values with a TTL must be reverted with
+// RevertResult, or with RevertWithOptions given the same ExpiresAt; plain
+// Revert fails authentication.
+//
+// Parameters:
+// - value: The sensitive value to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+// - ttl: How long the value may be reverted; must be positive
+//
+// Returns:
+// - Result containing pseudonymization artifacts and ExpiresAt
+// - error if operation fails or ttl is not positive
+func (s *Service) PseudonymizeWithTTL(value, purpose, system string, ttl time.Duration) (*Result, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTTL, ttl)
+	}
+	return s.pseudonymize(context.Background(), value, PseudonymizeOptions{
+		Purpose: purpose,
+		System:  system,
+		TTL:     ttl,
+	})
+}
+
+// PseudonymizeWith works like Pseudonymize, but uses pseudonym as
+// Result.Pseudonym instead of generating a UUID, for systems that already
+// have a stable external identifier. The value is still hashed and encrypted.
+//
+// The pseudonym must be non-empty, at most 255 bytes of UTF-8 and free of
+// whitespace and control characters; services created with WithUUIDPseudonyms
+// additionally require a UUID. It must not be derived from the value itself
+// (a CPF, an email address...), or the Result would no longer be
+// pseudonymous.
+//
+// Parameters:
+// - value: The sensitive value to pseudonymize
+// - pseudonym: The identifier to use as pseudonym
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Result containing pseudonymization artifacts
+// - error wrapping ErrInvalidPseudonym if pseudonym is not acceptable
+func (s *Service) PseudonymizeWith(value, pseudonym, purpose, system string) (*Result, error) {
+	if pseudonym == "" {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidPseudonym)
+	}
+	return s.pseudonymize(context.Background(), value, PseudonymizeOptions{
+		Purpose:   purpose,
+		System:    system,
+		Pseudonym: pseudonym,
+	})
+}
+
+// validateSuppliedPseudonym checks a caller-supplied pseudonym
+func (s *Service) validateSuppliedPseudonym(pseudonym string) error {
+	if !validPseudonym(pseudonym) {
+		return fmt.Errorf("%w: must be 1 to %d bytes without whitespace or control characters", ErrInvalidPseudonym, maxPseudonymLength)
+	}
+	if s.uuidOnly {
+		if _, err := uuid.Parse(pseudonym); err != nil {
+			return fmt.Errorf("%w: not a UUID", ErrInvalidPseudonym)
+		}
+	}
+	return nil
+}
+
+// PseudonymizeLight returns only a random pseudonym and the hash of value,
+// skipping encryption and the Result allocation. It suits write-heavy paths
+// that do not need the value to be reversible, or that encrypt it separately
+// and asynchronously with Encrypt. The hash is never salted, even with
+// WithSaltedHash.
+//
+// Parameters:
+// - value: The sensitive value to pseudonymize
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Random pseudonym (UUID v4 unless WithPseudonymGenerator is set)
+// - Hash, in the service's hash encoding (keyed when an HMAC key is set)
+// - error if value is empty or the service is closed
+func (s *Service) PseudonymizeLight(value, purpose, system string) (pseudonym, hash string, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if err = s.checkLength(len(value)); err != nil {
+		return "", "", err
+	}
+	if value, err = s.normalizeInput(value); err != nil {
+		return "", "", err
+	}
+	if len(value) == 0 {
+		return "", "", ErrEmptyValue
+	}
+	if err := s.checkNotPseudonym(value); err != nil {
+		return "", "", err
+	}
+
+	if hash, err = s.originalHash(value); err != nil {
+		return "", "", err
+	}
+	pseudonym = s.newPseudonym()
+
+	s.audit(context.Background(), AuditEvent{
+		Operation:    OperationPseudonymize,
+		OriginalHash: hash,
+		Pseudonym:    pseudonym,
+		Purpose:      purpose,
+		System:       system,
+	})
+	return pseudonym, hash, nil
+}
+
+// Anonymize irreversibly replaces value with a random pseudonym and its keyed
+// hash. Unlike Pseudonymize, the original value is not encrypted: the Result
+// has an empty EncryptedValue, Revert on it fails with ErrNotReversible and
+// the operation is audited as OperationAnonymize.
+//
+// The hash is always an HMAC, so the service must be created with
+// WithHMACKey: a plain hash of a low-entropy value such as a CPF can be
+// reversed by brute force, which would defeat anonymization. Note that whoever
+// holds the HMAC key can still confirm a guessed value against the hash, so
+// the key must be protected (or destroyed) for the data to count as anonymous
+// under the LGPD.
+//
+// Parameters:
+// - value: The sensitive value to anonymize
+// - purpose: Reason for anonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - Result with OriginalHash, Pseudonym and Timestamp only
+// - error if value is empty, no HMAC key is configured or the service is closed
+func (s *Service) Anonymize(value, purpose, system string) (_ *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if err := s.checkLength(len(value)); err != nil {
+		return nil, err
+	}
+	if value, err = s.normalizeInput(value); err != nil {
+		return nil, err
+	}
 	if len(value) == 0 {
-		return nil, errors.New("value cannot be empty")
+		return nil, ErrEmptyValue
+	}
+	if err := s.checkNotPseudonym(value); err != nil {
+		return nil, err
+	}
+	if !s.ring.keyed() {
+		return nil, ErrNoHMACKey
+	}
+
+	hash, err := s.originalHash(value)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{
+		OriginalHash: hash,
+		Pseudonym:    s.newPseudonym(),
+		Timestamp:    s.clock().Unix(),
+	}
+
+	s.audit(context.Background(), AuditEvent{
+		Operation:    OperationAnonymize,
+		OriginalHash: result.OriginalHash,
+		Pseudonym:    result.Pseudonym,
+		Purpose:      purpose,
+		System:       system,
+	})
+	return result, nil
+}
+
+// pseudonymize validates value, picks a random or deterministic pseudonym
+// according to opts and builds the Result, reporting it to the Observer
+func (s *Service) pseudonymize(ctx context.Context, value string, opts PseudonymizeOptions) (result *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+	return s.pseudonymizeUnobserved(ctx, value, opts)
+}
+
+// pseudonymizeUnobserved is pseudonymize without the Observer report, for
+// operations that report themselves
+func (s *Service) pseudonymizeUnobserved(ctx context.Context, value string, opts PseudonymizeOptions) (result *Result, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkLength(len(value)); err != nil {
+		return nil, err
+	}
+	if value, err = s.normalizeInput(value); err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, ErrEmptyValue
+	}
+	if err := s.checkNotPseudonym(value); err != nil {
+		return nil, err
+	}
+	if opts.TTL < 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTTL, opts.TTL)
 	}
 
-	// Generate SHA-256 hash of original value
-	hash := sha256.Sum256([]byte(value))
-	hashStr := hex.EncodeToString(hash[:])
+	// Generate a random pseudonym unless a stable or supplied one was requested
+	var pseudonym string
+	switch {
+	case opts.Pseudonym != "" && opts.Deterministic:
+		return nil, fmt.Errorf("%w: a supplied pseudonym cannot be deterministic", ErrInvalidPseudonym)
+	case opts.Pseudonym != "":
+		if err := s.validateSuppliedPseudonym(opts.Pseudonym); err != nil {
+			return nil, err
+		}
+		pseudonym = opts.Pseudonym
+	case opts.Deterministic:
+		if pseudonym, err = s.deterministicPseudonym(value); err != nil {
+			return nil, err
+		}
+	default:
+		pseudonym = s.newPseudonym()
+	}
+	return s.newResult(ctx, value, pseudonym, opts)
+}
+
+// newResult hashes and encrypts value, binding purpose, system and any
+// additional data to the ciphertext, assembles the Result for pseudonym and
+// records the operation in the audit log
+func (s *Service) newResult(ctx context.Context, value, pseudonym string, opts PseudonymizeOptions) (*Result, error) {
+	// Checked before hashing, for callers that do not check it themselves
+	if err := s.checkLength(len(value)); err != nil {
+		return nil, err
+	}
+
+	// Generate hash of original value (keyed when an HMAC key is configured)
+	var hashStr, salt string
+	var err error
+	if s.saltedHash {
+		hashStr, salt, err = s.hashWithSalt(value)
+	} else {
+		hashStr, err = s.originalHash(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clock := s.clock
+	if opts.Clock != nil {
+		clock = opts.Clock
+	}
+	now := clock()
+
+	var expiresAt int64
+	if opts.TTL > 0 {
+		expiresAt = now.Add(opts.TTL).Unix()
+	}
 
 	// Encrypt the original value
-	encrypted, err := s.encrypt(value)
+	aad := s.additionalData(opts.Purpose, opts.System, expiryAAD(expiresAt, opts.AdditionalData))
+	encrypted, err := s.EncryptWithAAD(value, aad)
 	if err != nil {
 		return nil, fmt.Errorf("encryption failed: %w", err)
 	}
 
-	// Generate UUID v4 pseudonym
-	pseudonym := uuid.New().String()
-
-	return &Result{
+	result := &Result{
 		OriginalHash:   hashStr,
+		HashSalt:       salt,
 		Pseudonym:      pseudonym,
 		EncryptedValue: encrypted,
-		Timestamp:      time.Now().Unix(),
-	}, nil
+		Timestamp:      now.Unix(),
+		ExpiresAt:      expiresAt,
+	}
+	if opts.OmitTimestamp {
+		result.Timestamp = 0
+	}
+	if s.detectPII {
+		result.PIIType = DetectPIIType(value)
+	}
+
+	s.audit(ctx, AuditEvent{
+		Operation:    OperationPseudonymize,
+		OriginalHash: result.OriginalHash,
+		Pseudonym:    result.Pseudonym,
+		Purpose:      opts.Purpose,
+		System:       opts.System,
+	})
+	return result, nil
+}
+
+// checkNotPseudonym returns ErrAlreadyPseudonymized in strict mode if value
+// parses as a UUID, or has the shape of a ULID when the service generates
+// ULIDs, the forms of the service's own pseudonyms
+func (s *Service) checkNotPseudonym(value string) error {
+	if !s.strict {
+		return nil
+	}
+	if _, err := uuid.Parse(value); err == nil {
+		return ErrAlreadyPseudonymized
+	}
+	if _, ok := s.generator.(ULIDGenerator); ok && isULID(value) {
+		return ErrAlreadyPseudonymized
+	}
+	return nil
+}
+
+// deterministicPseudonym derives a UUID v5 from value within the service
+// namespace, hashing value with the HMAC key first when one is configured,
+// and runs it past the collision checker
+func (s *Service) deterministicPseudonym(value string) (string, error) {
+	name := []byte(value)
+	if s.ring.keyed() {
+		var err error
+		if name, err = s.ring.digest(name); err != nil {
+			return "", err
+		}
+	}
+	pseudonym := uuid.NewSHA1(s.namespace, name).String()
+	if err := s.checkCollision(pseudonym, value); err != nil {
+		return "", err
+	}
+	return pseudonym, nil
 }
 
 // Revert decrypts an encrypted value back to its original form
@@ -80,70 +653,596 @@ func (s *Service) Pseudonymize(value, purpose, system string) (*Result, error) {
 // - encryptedValue: Base64-encoded encrypted value
 //
 // Returns:
-// - Original plaintext value
-// - error if decryption fails
+//   - Original plaintext value
+//   - error if decryption fails: ErrNotReversible for an empty value (as in
+//     a Result from Anonymize), ErrMalformedCiphertext for invalid base64,
+//     ErrCiphertextTooShort for truncated input, ErrDecryptionFailed when
+//     authentication fails (wrong key or tampered ciphertext) and
+//     ErrRateLimited when a RateLimiter denies the attempt
 func (s *Service) Revert(encryptedValue string) (string, error) {
-	plaintext, err := s.decrypt(encryptedValue)
+	return s.RevertContext(context.Background(), encryptedValue)
+}
+
+// RevertContext is like Revert but honours cancellation and deadlines of ctx,
+// which is also passed on to audit hooks
+//
+// Parameters:
+// - ctx: Request-scoped context; no work is done if it is already done
+// - encryptedValue: Base64-encoded encrypted value
+//
+// Returns:
+// - Original plaintext value
+// - error if decryption fails or ctx is done
+func (s *Service) RevertContext(ctx context.Context, encryptedValue string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.revert(ctx, encryptedValue, RevertOptions{})
+}
+
+// RevertWithContext decrypts an encrypted value produced for the given
+// purpose and system. When the service was created with WithPurposeBinding,
+// purpose and system are authenticated as AES-GCM additional data, so
+// reverting under a different purpose or system than the one used to
+// pseudonymize fails authentication. Without purpose binding it behaves
+// exactly like Revert.
+//
+// Parameters:
+// - encryptedValue: Base64-encoded encrypted value
+// - purpose: Purpose the value was pseudonymized for
+// - system: System the value was pseudonymized by
+//
+// Returns:
+// - Original plaintext value
+// - error if decryption or authentication fails
+func (s *Service) RevertWithContext(encryptedValue, purpose, system string) (string, error) {
+	return s.revert(context.Background(), encryptedValue, RevertOptions{Purpose: purpose, System: system})
+}
+
+// RevertWithOptions is the counterpart of PseudonymizeWithOptions. Purpose,
+// system (when purpose binding is enabled) and AdditionalData must match the
+// values the ciphertext was produced with, otherwise authentication fails.
+//
+// Parameters:
+// - encryptedValue: Base64-encoded encrypted value
+// - opts: Context the value was pseudonymized under
+//
+// Returns:
+// - Original plaintext value
+// - error if decryption or authentication fails
+func (s *Service) RevertWithOptions(encryptedValue string, opts RevertOptions) (string, error) {
+	return s.revert(context.Background(), encryptedValue, opts)
+}
+
+// RevertResult reverts a Result, taking its ExpiresAt into account: it fails
+// with ErrExpired once the result has expired, unless opts.IgnoreExpiry is
+// set. opts.ExpiresAt is ignored in favour of result.ExpiresAt.
+//
+// Parameters:
+// - result: Result produced by one of the Pseudonymize methods
+// - opts: Context the value was pseudonymized under
+//
+// Returns:
+// - Original plaintext value
+// - error if the result expired, or decryption or authentication fails
+func (s *Service) RevertResult(result *Result, opts RevertOptions) (string, error) {
+	opts.ExpiresAt = result.ExpiresAt
+	return s.revert(context.Background(), result.EncryptedValue, opts)
+}
+
+// revert decrypts encryptedValue, authenticating the context in opts, and
+// records the re-identification in the audit log
+func (s *Service) revert(ctx context.Context, encryptedValue string, opts RevertOptions) (_ string, err error) {
+	defer s.observeRevert(time.Now(), &err)
+
+	if encryptedValue == "" {
+		return "", ErrNotReversible
+	}
+	if opts.ExpiresAt != 0 && !opts.IgnoreExpiry && !s.clock().Before(time.Unix(opts.ExpiresAt, 0)) {
+		return "", ErrExpired
+	}
+
+	if err := s.allowRevert(ctx); err != nil {
+		return "", err
+	}
+
+	aad := s.additionalData(opts.Purpose, opts.System, expiryAAD(opts.ExpiresAt, opts.AdditionalData))
+	plaintext, err := s.DecryptWithAAD(encryptedValue, aad)
 	if err != nil {
-		return "", fmt.Errorf("decryption failed: %w", err)
+		return "", err
 	}
+
+	hash, _ := s.originalHash(plaintext)
+	s.audit(ctx, AuditEvent{
+		Operation:    OperationRevert,
+		OriginalHash: hash,
+		Purpose:      opts.Purpose,
+		System:       opts.System,
+	})
 	return plaintext, nil
 }
 
-// Hash generates a SHA-256 hash of a value (hex encoded)
+// VerifyRoundTrip is a self-test: it hashes and encrypts original the way
+// Pseudonymize does, decrypts the result again and checks that both the
+// plaintext and the hash match. Run it at startup with a known canary value
+// to confirm that the key and the implementation are consistent before
+// processing real data. Nothing is audited or reported to the observer.
+//
+// Parameters:
+// - original: A non-sensitive canary value
+//
+// Returns:
+//   - nil if the value round-trips
+//   - error wrapping ErrRoundTripFailed describing the mismatch, or the
+//     error of the failing step (e.g. ErrServiceClosed)
+func (s *Service) VerifyRoundTrip(original string) error {
+	original = s.normalize(original)
+	if len(original) == 0 {
+		return ErrEmptyValue
+	}
+
+	hash, err := s.originalHash(original)
+	if err != nil {
+		return err
+	}
+	aad := s.additionalData("", "", nil)
+	encrypted, err := s.EncryptWithAAD(original, aad)
+	if err != nil {
+		return fmt.Errorf("encryption failed: %w", err)
+	}
+
+	reverted, err := s.DecryptWithAAD(encrypted, aad)
+	if err != nil {
+		return fmt.Errorf("%w: decrypting a fresh ciphertext: %v", ErrRoundTripFailed, err)
+	}
+	if reverted != original {
+		return fmt.Errorf("%w: reverted value differs from the original (%d bytes, want %d)", ErrRoundTripFailed, len(reverted), len(original))
+	}
+	if !s.VerifyHash(original, hash) {
+		return fmt.Errorf("%w: hash is not reproducible", ErrRoundTripFailed)
+	}
+	return nil
+}
+
+// additionalData combines the purpose binding AAD with caller supplied
+// additional data. Without extra data the encoding is exactly contextAAD, so
+// ciphertexts produced before AdditionalData existed still authenticate.
+// Otherwise the system is length-prefixed as well, keeping the boundary
+// between system and extra data unambiguous.
+func (s *Service) additionalData(purpose, system string, extra []byte) []byte {
+	if len(extra) == 0 {
+		return s.contextAAD(purpose, system)
+	}
+	if !s.bindPurpose {
+		return extra
+	}
+
+	var size [4]byte
+	aad := make([]byte, 0, 8+len(purpose)+len(system)+len(extra))
+	binary.BigEndian.PutUint32(size[:], uint32(len(purpose)))
+	aad = append(append(aad, size[:]...), purpose...)
+	binary.BigEndian.PutUint32(size[:], uint32(len(system)))
+	aad = append(append(aad, size[:]...), system...)
+	return append(aad, extra...)
+}
+
+// expiryAAD prepends a non-zero expiry to the caller's additional data, so
+// that it is authenticated with the ciphertext
+func expiryAAD(expiresAt int64, extra []byte) []byte {
+	if expiresAt == 0 {
+		return extra
+	}
+	aad := make([]byte, 8, 8+len(extra))
+	binary.BigEndian.PutUint64(aad, uint64(expiresAt))
+	return append(aad, extra...)
+}
+
+// contextAAD encodes purpose and system as additional authenticated data, or
+// returns nil when purpose binding is disabled. The purpose is length-prefixed
+// so that distinct (purpose, system) pairs never encode to the same bytes.
+func (s *Service) contextAAD(purpose, system string) []byte {
+	if !s.bindPurpose {
+		return nil
+	}
+
+	aad := make([]byte, 4, 4+len(purpose)+len(system))
+	binary.BigEndian.PutUint32(aad, uint32(len(purpose)))
+	aad = append(aad, purpose...)
+	return append(aad, system...)
+}
+
+// Hash generates an unkeyed hash of a value with the service's hash
+// algorithm, SHA-256 unless set with WithHashAlgorithm, encoded as set with
+// WithHashEncoding (hex by default)
 func (s *Service) Hash(value string) string {
-	hash := sha256.Sum256([]byte(value))
-	return hex.EncodeToString(hash[:])
+	return s.hashEncoding.encode(s.ring.hashAlg.sum([]byte(s.normalize(value))))
 }
 
-// encrypt performs AES-GCM encryption of plaintext
-func (s *Service) encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher(s.encryptionKey)
+// HashKeyed generates an HMAC of a value (encoded like Hash) using the
+// service's HMAC key. Unlike Hash, the result cannot be reproduced by someone
+// who only guesses the original value, which protects low-entropy inputs such
+// as CPFs against dictionary and rainbow-table attacks.
+//
+// If the service was created without WithHMACKey, HashKeyed falls back to
+// Hash. It returns an empty string once the service is closed.
+func (s *Service) HashKeyed(value string) string {
+	hash, _ := s.originalHash(s.normalize(value))
+	return hash
+}
+
+// VerifyHash reports whether value hashes to expectedHash, a hash as stored
+// in Result.OriginalHash (keyed when an HMAC key is configured) in the
+// service's hash encoding. The comparison runs in constant time, so it does
+// not leak how much of the hash matched. An expectedHash that does not decode
+// yields false.
+func (s *Service) VerifyHash(value, expectedHash string) bool {
+	expected, err := s.hashEncoding.decode(expectedHash)
 	if err != nil {
-		return "", err
+		return false
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	actual, err := s.ring.digest([]byte(s.normalize(value)))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(actual, expected)
+}
+
+// Rehash decrypts encryptedValue and returns the hash of the original value
+// under the service's current hashing configuration, i.e. what HashKeyed
+// would return for it. The plaintext never reaches the caller and its buffer
+// is zeroed before returning, so a migration job holding only ciphertexts and
+// the key can upgrade stored hashes in place, e.g. from plain SHA-256 to
+// HMAC after adding WithHMACKey, or to another WithHashAlgorithm.
+//
+// Like ReEncrypt, Rehash records no audit event and cannot read values bound
+// to a purpose and system with WithPurposeBinding. It ignores WithSaltedHash:
+// the returned hash is always unsalted.
+//
+// Parameters:
+// - encryptedValue: Base64-encoded encrypted value, as in Result.EncryptedValue
+//
+// Returns:
+//   - Hash of the original value, in the service's hash encoding
+//   - error: ErrNotReversible for an empty value, or any error of
+//     DecryptBytes
+func (s *Service) Rehash(encryptedValue string) (newHash string, err error) {
+	if encryptedValue == "" {
+		return "", ErrNotReversible
+	}
+
+	plaintext, err := s.decryptBytesWithAAD(encryptedValue, nil)
 	if err != nil {
 		return "", err
 	}
+	defer wipe(plaintext)
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = rand.Read(nonce); err != nil {
+	if s.normalizer != nil {
+		return s.originalHash(s.normalizer(string(plaintext)))
+	}
+	hash, err := s.ring.digest(plaintext)
+	if err != nil {
 		return "", err
 	}
+	return s.hashEncoding.encode(hash), nil
+}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+// HashWithSalt hashes value together with a fresh random salt, so equal
+// values get different hashes and precomputed (rainbow) tables are useless.
+// The hash is an HMAC when an HMAC key is configured and a plain hash
+// otherwise, computed over salt || value with the service's hash algorithm.
+// It returns empty strings once the service is closed.
+//
+// Salted hashes cannot be used for equality joins or lookups, because the
+// same value never hashes the same way twice; use HashKeyed when a
+// deterministic but non-reproducible hash is needed.
+//
+// Parameters:
+// - value: The value to hash
+//
+// Returns:
+// - Hash, in the service's hash encoding
+// - Hex-encoded salt, to be stored with the hash for VerifySaltedHash
+func (s *Service) HashWithSalt(value string) (hash, salt string) {
+	hash, salt, _ = s.hashWithSalt(s.normalize(value))
+	return hash, salt
 }
 
-// decrypt performs AES-GCM decryption of ciphertext
-func (s *Service) decrypt(ciphertext string) (string, error) {
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+// hashWithSalt is HashWithSalt with error reporting
+func (s *Service) hashWithSalt(value string) (hash, salt string, err error) {
+	raw := make([]byte, saltSize)
+	if _, err := io.ReadFull(s.ring.random, raw); err != nil {
+		return "", "", err
+	}
+
+	digest, err := s.saltedDigest(value, raw)
+	if err != nil {
+		return "", "", err
+	}
+	return s.hashEncoding.encode(digest), hex.EncodeToString(raw), nil
+}
+
+// VerifySaltedHash reports whether value hashes to expectedHash under salt,
+// as returned by HashWithSalt or stored in Result.OriginalHash and
+// Result.HashSalt. The comparison runs in constant time. An expectedHash that
+// does not decode in the service's hash encoding, or malformed hex in salt,
+// yields false.
+func (s *Service) VerifySaltedHash(value, expectedHash, salt string) bool {
+	expected, err := s.hashEncoding.decode(expectedHash)
+	if err != nil {
+		return false
+	}
+	rawSalt, err := hex.DecodeString(salt)
+	if err != nil || len(rawSalt) == 0 {
+		return false
+	}
+
+	actual, err := s.saltedDigest(s.normalize(value), rawSalt)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(actual, expected)
+}
+
+// saltedDigest returns the digest of salt || value
+func (s *Service) saltedDigest(value string, salt []byte) ([]byte, error) {
+	data := make([]byte, 0, len(salt)+len(value))
+	data = append(append(data, salt...), value...)
+	return s.ring.digest(data)
+}
+
+// originalHash computes the hash stored in Result.OriginalHash: keyed when an
+// HMAC key is configured, a plain hash otherwise
+func (s *Service) originalHash(value string) (string, error) {
+	hash, err := s.ring.digest([]byte(value))
 	if err != nil {
 		return "", err
 	}
+	return s.hashEncoding.encode(hash), nil
+}
+
+// Close overwrites the encryption and HMAC keys held by the service with
+// zeros and makes every subsequent operation that needs them fail with
+// ErrServiceClosed. It waits for in-flight operations to finish and is safe
+// to call concurrently and more than once.
+//
+// This is a best-effort, defense-in-depth measure: the Go runtime may have
+// copied key material elsewhere (e.g. while growing a slice, in the AES key
+// schedule or in the caller's own copy of the key), and the garbage
+// collector gives no guarantee about when such copies are reclaimed.
+func (s *Service) Close() error {
+	s.ring.close()
+	return nil
+}
+
+// errMissingKey is returned for a nil or empty encryption key, usually an
+// unset environment variable or secret rather than a key of the wrong size
+var errMissingKey = fmt.Errorf("%w: got 0 bytes, the encryption key is nil or empty", ErrInvalidKeyLength)
 
-	block, err := aes.NewCipher(s.encryptionKey)
+// validateKeyLength checks that key is a valid AES-128, AES-192 or AES-256 key
+func validateKeyLength(key []byte) error {
+	switch len(key) {
+	case 0:
+		return errMissingKey
+	case 16, 24, 32:
+		return nil
+	default:
+		return fmt.Errorf("%w: got %d bytes, want 16, 24 or 32", ErrInvalidKeyLength, len(key))
+	}
+}
+
+// checkKeyStrength rejects keys that were obviously never filled with random
+// bytes: all zeros, a single repeated byte, or any short pattern repeated to
+// the key length (e.g. "abcdabcd..."). A random key repeats itself this way
+// with probability at most 2^-64, so no real key is rejected. This is a
+// heuristic for common mistakes, not an entropy estimator: a key typed from
+// a keyboard or derived from a weak passphrase still passes.
+func checkKeyStrength(key []byte) error {
+	for period := 1; period <= len(key)/2; period++ {
+		if bytes.Equal(key[period:], key[:len(key)-period]) {
+			if period == 1 && key[0] == 0 {
+				return fmt.Errorf("%w: the key is all zeros, was it ever filled with random bytes?", ErrWeakKey)
+			}
+			return fmt.Errorf("%w: the key repeats a %d-byte pattern", ErrWeakKey, period)
+		}
+	}
+	return nil
+}
+
+// Encrypt encrypts an arbitrary value with the service key, without hashing
+// it, generating a pseudonym or recording an audit event. The output has the
+// same format as Result.EncryptedValue, so Revert and Decrypt both accept it.
+// Binary data that is not valid UTF-8 must go through EncryptBytes.
+//
+// Parameters:
+// - plaintext: The value to encrypt
+//
+// Returns:
+// - Base64-encoded encrypted value
+// - error: ErrInvalidUTF8, or if encryption fails or the service is closed
+func (s *Service) Encrypt(plaintext string) (string, error) {
+	if !utf8.ValidString(plaintext) {
+		return "", ErrInvalidUTF8
+	}
+	return s.EncryptBytes([]byte(plaintext))
+}
+
+// Decrypt decrypts a value produced by Encrypt (or Pseudonymize without
+// purpose binding). Unlike Revert it records no audit event, so prefer Revert
+// when the value identifies a person.
+//
+// Parameters:
+// - ciphertext: Base64-encoded encrypted value
+//
+// Returns:
+// - Original plaintext value
+// - error if decryption fails, classified like the errors of Revert
+func (s *Service) Decrypt(ciphertext string) (string, error) {
+	plaintext, err := s.DecryptBytes(ciphertext)
 	if err != nil {
 		return "", err
 	}
+	return string(plaintext), nil
+}
+
+// EncryptBytes is like Encrypt for binary data such as document fragments,
+// which need not be valid UTF-8
+//
+// Parameters:
+// - plaintext: The bytes to encrypt
+//
+// Returns:
+// - Base64-encoded encrypted value
+// - error if encryption fails or the service is closed
+func (s *Service) EncryptBytes(plaintext []byte) (string, error) {
+	return s.encryptBytesWithAAD(plaintext, nil)
+}
+
+// DecryptBytes is like Decrypt but returns the plaintext bytes exactly as
+// they were given to EncryptBytes
+//
+// Parameters:
+// - ciphertext: Base64-encoded encrypted value
+//
+// Returns:
+// - Original plaintext bytes
+// - error if decryption fails, classified like the errors of Revert
+func (s *Service) DecryptBytes(ciphertext string) ([]byte, error) {
+	return s.decryptBytesWithAAD(ciphertext, nil)
+}
+
+// encrypt is an alias of Encrypt
+func (s *Service) encrypt(plaintext string) (string, error) {
+	return s.Encrypt(plaintext)
+}
+
+// EncryptWithAAD is like Encrypt, but binds aad to the ciphertext as
+// additional authenticated data: aad is not stored in the output, and
+// DecryptWithAAD only succeeds when given the same bytes. Binding a record or
+// tenant ID this way prevents ciphertext substitution, where an encrypted
+// field is copied from one record into another.
+//
+// Parameters:
+// - plaintext: The value to encrypt
+// - aad: Context the ciphertext is bound to, e.g. a record ID; may be nil
+//
+// Returns:
+// - Base64-encoded encrypted value
+// - error if encryption fails or the service is closed
+func (s *Service) EncryptWithAAD(plaintext string, aad []byte) (string, error) {
+	if !utf8.ValidString(plaintext) {
+		return "", ErrInvalidUTF8
+	}
+	return s.encryptBytesWithAAD([]byte(plaintext), aad)
+}
+
+// encryptBytesWithAAD encrypts plaintext under the active key, authenticating
+// aad, and base64-encodes the result with the configured encoding. The
+// ciphertext and its encoding share one pooled buffer, so the returned string
+// is the only allocation of its own.
+func (s *Service) encryptBytesWithAAD(plaintext, aad []byte) (string, error) {
+	if err := s.checkLength(len(plaintext)); err != nil {
+		return "", err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
 
-	gcm, err := cipher.NewGCM(block)
+	sealed, err := s.ring.seal((*buf)[:0], plaintext, s.tenantAAD(aad))
 	if err != nil {
 		return "", err
 	}
 
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", errors.New("ciphertext too short")
+	// Encode into the same buffer, after the ciphertext
+	size := len(sealed)
+	out := slices.Grow(sealed, s.encoding.EncodedLen(size))
+	out = out[:size+s.encoding.EncodedLen(size)]
+	s.encoding.Encode(out[size:], out[:size])
+	*buf = out
+	return string(out[size:]), nil
+}
+
+// checkLength fails with ErrValueTooLarge if size exceeds the service's
+// maximum value length
+func (s *Service) checkLength(size int) error {
+	if s.maxValueLength > 0 && size > s.maxValueLength {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrValueTooLarge, size, s.maxValueLength)
 	}
+	return nil
+}
 
-	nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
-	plaintextBytes, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
+// maxPooledBufferSize bounds the buffers kept in bufferPool, so that one
+// large value does not pin its memory for the life of the process
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool holds scratch buffers for encryption. Each buffer is used by one
+// call at a time and never escapes it: results are copied out before the
+// buffer is returned.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// getBuffer takes a scratch buffer from bufferPool
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns buf to bufferPool, dropping oversized buffers
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBufferSize {
+		return
+	}
+	*buf = (*buf)[:0]
+	bufferPool.Put(buf)
+}
+
+// decrypt is an alias of Decrypt
+func (s *Service) decrypt(ciphertext string) (string, error) {
+	return s.Decrypt(ciphertext)
+}
+
+// DecryptWithAAD decrypts a value produced by EncryptWithAAD, authenticating
+// aad. Like Decrypt, it records no audit event.
+//
+// Parameters:
+// - ciphertext: Base64-encoded encrypted value
+// - aad: The additional data given to EncryptWithAAD
+//
+// Returns:
+//   - Original plaintext value
+//   - error if decryption fails, classified like the errors of Revert; a
+//     different aad yields ErrDecryptionFailed
+func (s *Service) DecryptWithAAD(ciphertext string, aad []byte) (string, error) {
+	plaintext, err := s.decryptBytesWithAAD(ciphertext, aad)
 	if err != nil {
 		return "", err
 	}
+	return string(plaintext), nil
+}
 
-	return string(plaintextBytes), nil
+// decryptBytesWithAAD decodes and decrypts ciphertext, authenticating aad
+func (s *Service) decryptBytesWithAAD(ciphertext string, aad []byte) ([]byte, error) {
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return s.ring.open(data, s.tenantAAD(aad))
+}
+
+// ciphertextAlphabet maps the URL-safe base64 alphabet onto the standard one
+var ciphertextAlphabet = strings.NewReplacer("-", "+", "_", "/")
+
+// decodeCiphertext decodes the base64 form of an encrypted value. Standard
+// and URL-safe alphabets, padded or not, are all accepted, so values stay
+// decodable whatever WithCiphertextEncoding was set to when they were stored.
+func decodeCiphertext(ciphertext string) ([]byte, error) {
+	normalized := strings.TrimRight(ciphertextAlphabet.Replace(ciphertext), "=")
+	data, err := base64.RawStdEncoding.DecodeString(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedCiphertext, err)
+	}
+	return data, nil
 }
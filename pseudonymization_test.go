@@ -1,9 +1,17 @@
 package pseudonymization
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,3 +69,1077 @@ func TestEncryption(t *testing.T) {
 	_, err = svc.decrypt("invalid-base64")
 	assert.Error(t, err)
 }
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	logger := &recordingAuditLogger{}
+	svc := NewService(key, WithAuditLogger(logger))
+
+	encrypted, err := svc.Encrypt("rua das flores, 123")
+	assert.NoError(t, err)
+	decrypted, err := svc.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "rua das flores, 123", decrypted)
+	assert.Empty(t, logger.events)
+
+	// Interchangeable with Pseudonymize and Revert
+	reverted, err := svc.Revert(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "rua das flores, 123", reverted)
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	decrypted, err = svc.Decrypt(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", decrypted)
+
+	_, err = svc.Decrypt("invalid-base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+}
+
+func TestEncryptDecryptWithAAD(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key)
+
+	encrypted, err := svc.EncryptWithAAD("52998224725", []byte("tenant-a/record-1"))
+	assert.NoError(t, err)
+	decrypted, err := svc.DecryptWithAAD(encrypted, []byte("tenant-a/record-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", decrypted)
+
+	// A ciphertext moved to another tenant's record fails authentication
+	_, err = svc.DecryptWithAAD(encrypted, []byte("tenant-b/record-1"))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = svc.DecryptWithAAD(encrypted, nil)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = svc.Decrypt(encrypted)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Nil additional data matches plain Encrypt and Decrypt
+	encrypted, err = svc.EncryptWithAAD("52998224725", nil)
+	assert.NoError(t, err)
+	decrypted, err = svc.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", decrypted)
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	for _, svc := range []*Service{
+		NewService(randomKey(t, 32), WithAuditLogger(logger)),
+		NewService(randomKey(t, 32), WithAuditLogger(logger), WithHMACKey(randomKey(t, 32)), WithPurposeBinding()),
+	} {
+		assert.NoError(t, svc.VerifyRoundTrip("self-test canary"))
+	}
+	assert.Empty(t, logger.events)
+
+	svc, err := NewServiceWithMode(randomKey(t, 64), ModeSIV)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.VerifyRoundTrip("self-test canary"))
+
+	assert.ErrorIs(t, svc.VerifyRoundTrip(""), ErrEmptyValue)
+	assert.NoError(t, svc.Close())
+	assert.ErrorIs(t, svc.VerifyRoundTrip("self-test canary"), ErrServiceClosed)
+}
+
+func TestWithCiphertextEncoding(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	// Long enough that every encoding shows its alphabet and padding
+	value := strings.Repeat("52998224725 ~~~ ", 8)
+
+	stdSvc := NewService(key)
+	stored, err := stdSvc.Pseudonymize(value, "test", "test")
+	assert.NoError(t, err)
+
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	} {
+		svc := NewService(key, WithCiphertextEncoding(encoding))
+
+		result, err := svc.Pseudonymize(value, "test", "test")
+		assert.NoError(t, err)
+		_, err = encoding.DecodeString(result.EncryptedValue)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Validate())
+
+		original, err := svc.Revert(result.EncryptedValue)
+		assert.NoError(t, err)
+		assert.Equal(t, value, original)
+
+		// Values stored before switching encodings, and values encoded by
+		// services with a different setting, still revert
+		original, err = svc.Revert(stored.EncryptedValue)
+		assert.NoError(t, err)
+		assert.Equal(t, value, original)
+		original, err = stdSvc.Revert(result.EncryptedValue)
+		assert.NoError(t, err)
+		assert.Equal(t, value, original)
+	}
+
+	svc := NewService(key, WithCiphertextEncoding(base64.RawURLEncoding))
+	for i := 0; i < 20; i++ {
+		result, err := svc.Pseudonymize(value, "test", "test")
+		assert.NoError(t, err)
+		assert.NotContains(t, result.EncryptedValue, "+")
+		assert.NotContains(t, result.EncryptedValue, "/")
+		assert.NotContains(t, result.EncryptedValue, "=")
+	}
+
+	_, err = svc.Revert("not base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+}
+
+func TestEncryptBytes(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key)
+
+	// Invalid UTF-8, NUL bytes and every byte value
+	plaintext := []byte{0xff, 0xfe, 0x00, 0xc3, 0x28, 0x80}
+	for b := 0; b < 256; b++ {
+		plaintext = append(plaintext, byte(b))
+	}
+
+	encrypted, err := svc.EncryptBytes(plaintext)
+	assert.NoError(t, err)
+	decrypted, err := svc.DecryptBytes(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Empty input round-trips too
+	encrypted, err = svc.EncryptBytes(nil)
+	assert.NoError(t, err)
+	decrypted, err = svc.DecryptBytes(encrypted)
+	assert.NoError(t, err)
+	assert.Empty(t, decrypted)
+
+	_, err = svc.DecryptBytes("invalid-base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+}
+
+func TestNewServiceWithError(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		key := make([]byte, size)
+		_, err := rand.Read(key)
+		assert.NoError(t, err)
+
+		svc, err := NewServiceWithError(key)
+		assert.NoError(t, err)
+		assert.NotNil(t, svc)
+	}
+
+	for _, size := range []int{0, 15, 20, 33} {
+		_, err := NewServiceWithError(make([]byte, size))
+		assert.ErrorIs(t, err, ErrInvalidKeyLength)
+		assert.Contains(t, err.Error(), fmt.Sprintf("got %d bytes", size))
+	}
+
+	assert.Panics(t, func() { NewService(make([]byte, 20)) })
+
+	// A missing key is reported as such, by every constructor
+	for _, key := range [][]byte{nil, {}} {
+		_, err := NewServiceWithError(key)
+		assert.ErrorIs(t, err, ErrInvalidKeyLength)
+		assert.Contains(t, err.Error(), "nil or empty")
+		_, err = NewServiceWithMode(key, ModeChaCha20Poly1305)
+		assert.ErrorIs(t, err, ErrInvalidKeyLength)
+		assert.Contains(t, err.Error(), "nil or empty")
+		_, err = NewServiceWithKeyring(map[int][]byte{1: key}, 1)
+		assert.ErrorIs(t, err, ErrInvalidKeyLength)
+		assert.PanicsWithValue(t,
+			"pseudonymization: invalid encryption key length: got 0 bytes, the encryption key is nil or empty",
+			func() { NewService(key) })
+	}
+}
+
+func TestWeakKey(t *testing.T) {
+	// A key buffer that was never filled
+	_, err := NewServiceWithError(make([]byte, 32))
+	assert.ErrorIs(t, err, ErrWeakKey)
+	assert.Contains(t, err.Error(), "all zeros")
+	assert.Panics(t, func() { NewService(make([]byte, 16)) })
+
+	for _, key := range [][]byte{
+		bytes.Repeat([]byte{0xff}, 32),
+		bytes.Repeat([]byte("ab"), 12),
+		bytes.Repeat([]byte("secret-k"), 4),
+		bytes.Repeat(randomKey(t, 16), 2),
+	} {
+		_, err := NewServiceWithError(key)
+		assert.ErrorIs(t, err, ErrWeakKey)
+	}
+
+	// Every constructor checks, whatever the mode
+	_, err = NewServiceWithMode(make([]byte, 64), ModeSIV)
+	assert.ErrorIs(t, err, ErrWeakKey)
+	_, err = NewServiceWithMode(make([]byte, 32), ModeChaCha20Poly1305)
+	assert.ErrorIs(t, err, ErrWeakKey)
+	_, err = NewServiceWithKeyring(map[int][]byte{1: randomKey(t, 32), 2: make([]byte, 32)}, 1)
+	assert.ErrorIs(t, err, ErrWeakKey)
+
+	// A pattern shorter than the key but not repeated passes
+	_, err = NewServiceWithError([]byte("0123456789abcdef0123456789abcdeX"))
+	assert.NoError(t, err)
+}
+
+func TestHashKeyed(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	hmacKey := make([]byte, 32)
+	_, err = rand.Read(hmacKey)
+	assert.NoError(t, err)
+
+	svc := NewService(key, WithHMACKey(hmacKey))
+
+	value := "529.982.247-25"
+	keyed := svc.HashKeyed(value)
+	assert.Len(t, keyed, 64)
+	assert.Equal(t, keyed, svc.HashKeyed(value))
+	assert.NotEqual(t, svc.Hash(value), keyed)
+
+	// Pseudonymize uses the keyed hash when an HMAC key is configured
+	result, err := svc.Pseudonymize(value, "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, keyed, result.OriginalHash)
+
+	// A different HMAC key yields a different hash
+	otherKey := make([]byte, 32)
+	_, err = rand.Read(otherKey)
+	assert.NoError(t, err)
+	assert.NotEqual(t, keyed, NewService(key, WithHMACKey(otherKey)).HashKeyed(value))
+
+	// Without an HMAC key, HashKeyed falls back to the plain hash
+	assert.Equal(t, svc.Hash(value), NewService(key).HashKeyed(value))
+
+	// Short HMAC keys are rejected
+	_, err = NewServiceWithError(key, WithHMACKey([]byte("short")))
+	assert.ErrorIs(t, err, ErrInvalidHMACKey)
+}
+
+func TestPseudonymizeDeterministic(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key)
+
+	first, err := svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.NoError(t, err)
+	second, err := svc.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	// Same value, same pseudonym, but the ciphertext still uses a fresh nonce
+	assert.Equal(t, first.Pseudonym, second.Pseudonym)
+	assert.NotEqual(t, first.EncryptedValue, second.EncryptedValue)
+
+	parsed, err := uuid.Parse(first.Pseudonym)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Version(5), parsed.Version())
+
+	other, err := svc.PseudonymizeDeterministic("11144477735", "test", "test")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Pseudonym, other.Pseudonym)
+
+	// A different namespace yields unlinkable pseudonyms
+	scoped := NewService(key, WithNamespace(uuid.New()))
+	third, err := scoped.PseudonymizeDeterministic("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Pseudonym, third.Pseudonym)
+
+	original, err := svc.Revert(first.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	_, err = svc.PseudonymizeDeterministic("", "test", "test")
+	assert.Error(t, err)
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	svc := NewService(key)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.encrypt("sensitive-data-123"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncryptParallel(b *testing.B) {
+	svc := NewService(randomKey(b, 32))
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := svc.encrypt("sensitive-data-123"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestEncryptConcurrentBufferReuse(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithEnvelopeFormat())
+
+	// Values of varying sizes, including one too large to be pooled, share
+	// pooled buffers across goroutines without corrupting each other
+	values := []string{"a", "52998224725", strings.Repeat("x", 1000), strings.Repeat("y", maxPooledBufferSize)}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				value := fmt.Sprintf("%s-%d-%d", values[(g+i)%len(values)], g, i)
+				encrypted, err := svc.Encrypt(value)
+				if !assert.NoError(t, err) {
+					return
+				}
+				original, err := svc.Decrypt(encrypted)
+				assert.NoError(t, err)
+				assert.Equal(t, value, original)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkDecrypt(b *testing.B) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	svc := NewService(key)
+	encrypted, err := svc.encrypt("sensitive-data-123")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.decrypt(encrypted); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPurposeBinding(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key, WithPurposeBinding())
+
+	result, err := svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+
+	original, err := svc.RevertWithContext(result.EncryptedValue, "billing", "erp")
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// A different purpose or system fails authentication
+	_, err = svc.RevertWithContext(result.EncryptedValue, "marketing", "erp")
+	assert.Error(t, err)
+	_, err = svc.RevertWithContext(result.EncryptedValue, "billing", "crm")
+	assert.Error(t, err)
+	_, err = svc.RevertWithContext(result.EncryptedValue, "billinge", "rp")
+	assert.Error(t, err)
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.Error(t, err)
+
+	// Without purpose binding the context is not authenticated
+	unbound := NewService(key)
+	result, err = unbound.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	original, err = unbound.RevertWithContext(result.EncryptedValue, "marketing", "crm")
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+}
+
+func TestContextCancellation(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key)
+
+	result, err := svc.PseudonymizeContext(context.Background(), "52998224725", "test", "test")
+	assert.NoError(t, err)
+	original, err := svc.RevertContext(context.Background(), result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = svc.PseudonymizeContext(ctx, "52998224725", "test", "test")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = svc.RevertContext(ctx, result.EncryptedValue)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestVerifyHash(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	hmacKey := make([]byte, 32)
+	_, err = rand.Read(hmacKey)
+	assert.NoError(t, err)
+
+	for _, svc := range []*Service{NewService(key), NewService(key, WithHMACKey(hmacKey))} {
+		result, err := svc.Pseudonymize("52998224725", "test", "test")
+		assert.NoError(t, err)
+
+		assert.True(t, svc.VerifyHash("52998224725", result.OriginalHash))
+		assert.False(t, svc.VerifyHash("52998224726", result.OriginalHash))
+		assert.False(t, svc.VerifyHash("52998224725", result.OriginalHash[:62]))
+		assert.False(t, svc.VerifyHash("52998224725", "not-hex"))
+		assert.False(t, svc.VerifyHash("52998224725", ""))
+	}
+}
+
+func TestRehash(t *testing.T) {
+	key := randomKey(t, 32)
+	hmacKey := randomKey(t, 32)
+
+	// Values hashed with plain SHA-256 are upgraded to HMAC from the ciphertext alone
+	legacy, err := NewService(key).Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	svc := NewService(key, WithHMACKey(hmacKey))
+	hash, err := svc.Rehash(legacy.EncryptedValue)
+	assert.NoError(t, err)
+	assert.NotEqual(t, legacy.OriginalHash, hash)
+	assert.Equal(t, svc.HashKeyed("52998224725"), hash)
+	assert.True(t, svc.VerifyHash("52998224725", hash))
+
+	// The configured normalizer and hash algorithm apply
+	upgraded := NewService(key, WithHMACKey(hmacKey), WithNormalizer(NormalizeCPF), WithHashAlgorithm(HashSHA512))
+	formatted, err := NewService(key).Pseudonymize("529.982.247-25", "test", "test")
+	assert.NoError(t, err)
+	hash, err = upgraded.Rehash(formatted.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, upgraded.HashKeyed("52998224725"), hash)
+	assert.Len(t, hash, 128)
+
+	_, err = svc.Rehash("")
+	assert.ErrorIs(t, err, ErrNotReversible)
+	_, err = svc.Rehash("not base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+	_, err = NewService(randomKey(t, 32)).Rehash(legacy.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestPseudonymizeWithOptions(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key, WithPurposeBinding())
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	result, err := svc.PseudonymizeWithOptions("52998224725", PseudonymizeOptions{
+		Purpose:        "billing",
+		System:         "erp",
+		Deterministic:  true,
+		Clock:          func() time.Time { return fixed },
+		AdditionalData: []byte("record-42"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fixed.Unix(), result.Timestamp)
+
+	deterministic, err := svc.PseudonymizeDeterministic("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	assert.Equal(t, deterministic.Pseudonym, result.Pseudonym)
+
+	original, err := svc.RevertWithOptions(result.EncryptedValue, RevertOptions{
+		Purpose:        "billing",
+		System:         "erp",
+		AdditionalData: []byte("record-42"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Additional data is authenticated alongside purpose and system
+	_, err = svc.RevertWithOptions(result.EncryptedValue, RevertOptions{
+		Purpose:        "billing",
+		System:         "erp",
+		AdditionalData: []byte("record-43"),
+	})
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = svc.RevertWithContext(result.EncryptedValue, "billing", "erp")
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = svc.RevertWithOptions(result.EncryptedValue, RevertOptions{
+		Purpose:        "billing",
+		System:         "erp" + "record-42",
+		AdditionalData: []byte{},
+	})
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// The zero value matches Pseudonymize and Revert
+	result, err = svc.PseudonymizeWithOptions("52998224725", PseudonymizeOptions{})
+	assert.NoError(t, err)
+	_, err = uuid.Parse(result.Pseudonym)
+	assert.NoError(t, err)
+	original, err = svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Without purpose binding additional data is still authenticated
+	unbound := NewService(key)
+	result, err = unbound.PseudonymizeWithOptions("52998224725", PseudonymizeOptions{AdditionalData: []byte("record-42")})
+	assert.NoError(t, err)
+	_, err = unbound.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	original, err = unbound.RevertWithOptions(result.EncryptedValue, RevertOptions{AdditionalData: []byte("record-42")})
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	_, err = svc.PseudonymizeWithOptions("", PseudonymizeOptions{})
+	assert.ErrorIs(t, err, ErrEmptyValue)
+}
+
+func TestWithClock(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	frozen := time.Date(2023, 6, 15, 12, 0, 0, 0, time.FixedZone("BRT", -3*60*60))
+	logger := &recordingAuditLogger{}
+	svc := NewService(key, WithClock(func() time.Time { return frozen }), WithAuditLogger(logger))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1686841200), result.Timestamp)
+
+	assert.Len(t, logger.events, 1)
+	assert.Equal(t, frozen.UTC(), logger.events[0].Timestamp)
+
+	// A per-call clock takes precedence over the service clock
+	later := frozen.Add(time.Hour)
+	result, err = svc.PseudonymizeWithOptions("52998224725", PseudonymizeOptions{Clock: func() time.Time { return later }})
+	assert.NoError(t, err)
+	assert.Equal(t, later.Unix(), result.Timestamp)
+
+	// A nil clock keeps time.Now
+	before := time.Now().Unix()
+	result, err = NewService(key, WithClock(nil)).Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, result.Timestamp, before)
+}
+
+func TestMaxValueLength(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)))
+	large := strings.Repeat("x", DefaultMaxValueLength+1)
+
+	_, err := svc.Pseudonymize(large, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = svc.Anonymize(large, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, _, err = svc.PseudonymizeLight(large, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = svc.PseudonymizeEmail(large+"@example.com", "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = svc.PseudonymizeName(large, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = svc.PseudonymizePhone(large, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = svc.PseudonymizeCPFFormatPreserving(large, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = NewService(randomKey(t, 32), WithTokenVault(NewMemoryTokenVault())).Tokenize(large, "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = svc.Encrypt(large)
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	_, err = svc.EncryptBytes([]byte(large))
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+
+	_, err = svc.Pseudonymize(large[:DefaultMaxValueLength], "test", "test")
+	assert.NoError(t, err)
+
+	// The limit can be lowered or disabled
+	small := NewService(randomKey(t, 32), WithMaxValueLength(11))
+	_, err = small.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = small.Pseudonymize("529.982.247-25", "test", "test")
+	assert.ErrorIs(t, err, ErrValueTooLarge)
+	assert.Contains(t, err.Error(), "14 bytes, limit is 11")
+
+	unlimited := NewService(randomKey(t, 32), WithMaxValueLength(0))
+	encrypted, err := unlimited.Encrypt(large)
+	assert.NoError(t, err)
+	original, err := unlimited.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, large, original)
+}
+
+func TestPseudonymizeOmitTimestamp(t *testing.T) {
+	key := randomKey(t, 32)
+	svc := NewService(key, WithHMACKey(randomKey(t, 32)), WithDeterministicEncryption())
+	opts := PseudonymizeOptions{Purpose: "test", System: "test", Deterministic: true, OmitTimestamp: true}
+
+	first, err := svc.PseudonymizeWithOptions("52998224725", opts)
+	assert.NoError(t, err)
+	assert.Zero(t, first.Timestamp)
+	assert.NoError(t, first.Validate())
+
+	// With deterministic pseudonyms and encryption the whole Result is reproducible
+	second, err := svc.PseudonymizeWithOptions("52998224725", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	original, err := svc.Revert(first.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// The clock still drives the TTL
+	frozen := time.Unix(1700000000, 0)
+	opts.TTL = time.Hour
+	opts.Clock = func() time.Time { return frozen }
+	expiring, err := svc.PseudonymizeWithOptions("52998224725", opts)
+	assert.NoError(t, err)
+	assert.Zero(t, expiring.Timestamp)
+	assert.Equal(t, frozen.Add(time.Hour).Unix(), expiring.ExpiresAt)
+	assert.NoError(t, expiring.Validate())
+}
+
+func TestPseudonymizeWith(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.PseudonymizeWith("52998224725", "customer-00042", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, "customer-00042", result.Pseudonym)
+	assert.Equal(t, svc.Hash("52998224725"), result.OriginalHash)
+	original, err := svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Results with supplied pseudonyms survive serialization
+	data, err := result.ToJSON()
+	assert.NoError(t, err)
+	decoded, err := ResultFromJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, result, decoded)
+	assert.NoError(t, result.Validate())
+
+	for _, pseudonym := range []string{"", "customer 42", "line\nbreak", strings.Repeat("x", 256)} {
+		_, err = svc.PseudonymizeWith("52998224725", pseudonym, "test", "test")
+		assert.ErrorIs(t, err, ErrInvalidPseudonym, pseudonym)
+	}
+	_, err = svc.PseudonymizeWith("", "customer-00042", "test", "test")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+	_, err = svc.PseudonymizeWithOptions("52998224725", PseudonymizeOptions{Pseudonym: "customer-00042", Deterministic: true})
+	assert.ErrorIs(t, err, ErrInvalidPseudonym)
+
+	// UUIDs can be required
+	svc = NewService(randomKey(t, 32), WithUUIDPseudonyms())
+	_, err = svc.PseudonymizeWith("52998224725", "customer-00042", "test", "test")
+	assert.ErrorIs(t, err, ErrInvalidPseudonym)
+	id := uuid.New().String()
+	result, err = svc.PseudonymizeWith("52998224725", id, "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, id, result.Pseudonym)
+}
+
+func TestPseudonymizeWithTTL(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewService(key, WithClock(func() time.Time { return now }))
+
+	result, err := svc.PseudonymizeWithTTL("52998224725", "test", "test", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(24*time.Hour).Unix(), result.ExpiresAt)
+	assert.False(t, result.IsExpired(now))
+	assert.True(t, result.IsExpired(now.Add(24*time.Hour)))
+
+	original, err := svc.RevertResult(result, RevertOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// The expiry is authenticated: plain Revert or an edited ExpiresAt fails
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	extended := *result
+	extended.ExpiresAt += 3600
+	_, err = svc.RevertResult(&extended, RevertOptions{})
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Once expired, only a legal hold override reverts the value
+	now = now.Add(25 * time.Hour)
+	_, err = svc.RevertResult(result, RevertOptions{})
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = svc.RevertWithOptions(result.EncryptedValue, RevertOptions{ExpiresAt: result.ExpiresAt})
+	assert.ErrorIs(t, err, ErrExpired)
+	original, err = svc.RevertResult(result, RevertOptions{IgnoreExpiry: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// Results without a TTL never expire
+	result, err = svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Zero(t, result.ExpiresAt)
+	assert.False(t, result.IsExpired(now.Add(100*365*24*time.Hour)))
+	_, err = svc.RevertResult(result, RevertOptions{})
+	assert.NoError(t, err)
+
+	_, err = svc.PseudonymizeWithTTL("52998224725", "test", "test", 0)
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+	_, err = svc.PseudonymizeWithOptions("52998224725", PseudonymizeOptions{TTL: -time.Second})
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+}
+
+func TestHashWithSalt(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	hmacKey := make([]byte, 32)
+	_, err = rand.Read(hmacKey)
+	assert.NoError(t, err)
+
+	for _, svc := range []*Service{NewService(key), NewService(key, WithHMACKey(hmacKey))} {
+		hash, salt := svc.HashWithSalt("52998224725")
+		assert.Len(t, hash, 64)
+		assert.Len(t, salt, 32)
+
+		// A fresh salt every time: equal values hash differently
+		other, otherSalt := svc.HashWithSalt("52998224725")
+		assert.NotEqual(t, hash, other)
+		assert.NotEqual(t, salt, otherSalt)
+		assert.NotEqual(t, svc.HashKeyed("52998224725"), hash)
+
+		assert.True(t, svc.VerifySaltedHash("52998224725", hash, salt))
+		assert.True(t, svc.VerifySaltedHash("52998224725", other, otherSalt))
+		assert.False(t, svc.VerifySaltedHash("52998224725", hash, otherSalt))
+		assert.False(t, svc.VerifySaltedHash("52998224726", hash, salt))
+		assert.False(t, svc.VerifySaltedHash("52998224725", hash, "not-hex"))
+		assert.False(t, svc.VerifySaltedHash("52998224725", hash, ""))
+	}
+}
+
+func TestWithSaltedHash(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := NewService(key, WithSaltedHash())
+	first, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	second, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, first.HashSalt)
+	assert.NotEqual(t, first.OriginalHash, second.OriginalHash)
+	assert.True(t, svc.VerifySaltedHash("52998224725", first.OriginalHash, first.HashSalt))
+	assert.False(t, svc.VerifyHash("52998224725", first.OriginalHash))
+
+	// The salt survives serialization
+	data, err := first.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded Result
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, *first, decoded)
+
+	// Unsalted services leave HashSalt empty
+	result, err := NewService(key).Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Empty(t, result.HashSalt)
+
+	assert.NoError(t, svc.Close())
+	hash, salt := svc.HashWithSalt("52998224725")
+	assert.Empty(t, hash)
+	assert.Empty(t, salt)
+	_, err = svc.Pseudonymize("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+}
+
+// TestConcurrentUse shares one Service across goroutines; run with -race
+func TestConcurrentUse(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	hmacKey := make([]byte, 32)
+	_, err = rand.Read(hmacKey)
+	assert.NoError(t, err)
+
+	svc := NewService(key,
+		WithHMACKey(hmacKey),
+		WithAuditLogger(&recordingAuditLogger{}),
+		WithTokenVault(NewMemoryTokenVault()))
+
+	const goroutines, iterations = 16, 50
+	errs := make(chan error, goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			for i := 0; i < iterations; i++ {
+				value := fmt.Sprintf("value-%d-%d", g, i)
+
+				result, err := svc.Pseudonymize(value, "test", "test")
+				if err != nil {
+					errs <- err
+					return
+				}
+				original, err := svc.Revert(result.EncryptedValue)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if original != value || !svc.VerifyHash(value, result.OriginalHash) {
+					errs <- fmt.Errorf("round trip of %q returned %q", value, original)
+					return
+				}
+
+				if _, err := svc.PseudonymizeDeterministic(value, "test", "test"); err != nil {
+					errs <- err
+					return
+				}
+				token, err := svc.Tokenize(value, "test", "test")
+				if err != nil {
+					errs <- err
+					return
+				}
+				if _, err := svc.Detokenize(token); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}(g)
+	}
+
+	for g := 0; g < goroutines; g++ {
+		assert.NoError(t, <-errs)
+	}
+}
+
+func TestPseudonymizeLight(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	logger := &recordingAuditLogger{}
+	svc := NewService(key, WithAuditLogger(logger))
+
+	pseudonym, hash, err := svc.PseudonymizeLight("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = uuid.Parse(pseudonym)
+	assert.NoError(t, err)
+	assert.Equal(t, svc.HashKeyed("52998224725"), hash)
+	assert.True(t, svc.VerifyHash("52998224725", hash))
+
+	assert.Len(t, logger.events, 1)
+	assert.Equal(t, pseudonym, logger.events[0].Pseudonym)
+
+	_, _, err = svc.PseudonymizeLight("", "test", "test")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+
+	assert.NoError(t, svc.Close())
+	_, _, err = svc.PseudonymizeLight("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+}
+
+func TestAnonymize(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	hmacKey := make([]byte, 32)
+	_, err = rand.Read(hmacKey)
+	assert.NoError(t, err)
+
+	logger := &recordingAuditLogger{}
+	svc := NewService(key, WithHMACKey(hmacKey), WithAuditLogger(logger))
+
+	result, err := svc.Anonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Empty(t, result.EncryptedValue)
+	assert.Equal(t, svc.HashKeyed("52998224725"), result.OriginalHash)
+	assert.NotEqual(t, svc.Hash("52998224725"), result.OriginalHash)
+	_, err = uuid.Parse(result.Pseudonym)
+	assert.NoError(t, err)
+	assert.NotZero(t, result.Timestamp)
+
+	assert.Len(t, logger.events, 1)
+	assert.Equal(t, OperationAnonymize, logger.events[0].Operation)
+	assert.Equal(t, result.Pseudonym, logger.events[0].Pseudonym)
+
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrNotReversible)
+	_, err = svc.RevertWithContext(result.EncryptedValue, "test", "test")
+	assert.ErrorIs(t, err, ErrNotReversible)
+
+	_, err = svc.Anonymize("", "test", "test")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+
+	// Without an HMAC key the hash could be brute-forced
+	_, err = NewService(key).Anonymize("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrNoHMACKey)
+
+	assert.NoError(t, svc.Close())
+	_, err = svc.Anonymize("52998224725", "test", "test")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+}
+
+func BenchmarkPseudonymize(b *testing.B) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	svc := NewService(key)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.Pseudonymize("52998224725", "bench", "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPseudonymizeLight(b *testing.B) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	svc := NewService(key)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := svc.PseudonymizeLight("52998224725", "bench", "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithStrictMode())
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	// Pseudonymizing the pseudonym again is caught
+	_, err = svc.Pseudonymize(result.Pseudonym, "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	_, err = svc.PseudonymizeDeterministic(strings.ToUpper(result.Pseudonym), "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	_, err = svc.PseudonymizeBatch([]string{"52998224725", result.Pseudonym}, "test", "test")
+	assert.ErrorIs(t, err.(*BatchError).Errors[1], ErrAlreadyPseudonymized)
+	_, err = svc.PseudonymizeAll(map[string]string{"id": result.Pseudonym}, "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	_, err = svc.PseudonymizeName(result.Pseudonym, "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	_, err = svc.PseudonymizeEmail(result.Pseudonym, "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	_, _, err = svc.PseudonymizeLight(result.Pseudonym, "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	_, err = NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)), WithStrictMode()).Anonymize(result.Pseudonym, "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+	_, err = NewService(randomKey(t, 32), WithTokenVault(NewMemoryTokenVault()), WithStrictMode()).Tokenize(result.Pseudonym, "test", "test")
+	assert.ErrorIs(t, err, ErrAlreadyPseudonymized)
+
+	// Without strict mode UUIDs are ordinary values
+	_, err = NewService(randomKey(t, 32)).Pseudonymize(result.Pseudonym, "test", "test")
+	assert.NoError(t, err)
+}
+
+func TestWithRandomSource(t *testing.T) {
+	key := randomKey(t, 32)
+	seed := bytes.Repeat([]byte("deterministic entropy for tests!"), 64)
+	newService := func() *Service {
+		return NewService(key, WithRandomSource(bytes.NewReader(seed)), WithSaltedHash())
+	}
+	first, second := newService(), newService()
+
+	// Nonces come from the source, so ciphertexts are reproducible
+	ciphertext, err := first.Encrypt("52998224725")
+	assert.NoError(t, err)
+	expected, err := second.Encrypt("52998224725")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, ciphertext)
+
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Contains(raw, seed[:12]))
+
+	plaintext, err := first.Decrypt(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", plaintext)
+
+	// So are random pseudonyms and salts
+	result, err := first.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	other, err := second.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, other.Pseudonym, result.Pseudonym)
+	assert.Equal(t, other.HashSalt, result.HashSalt)
+	assert.Equal(t, other.EncryptedValue, result.EncryptedValue)
+
+	// And synthetic CPF pseudonyms
+	result, err = first.PseudonymizeCPFFormatPreserving("529.982.247-25", "test", "test")
+	assert.NoError(t, err)
+	other, err = second.PseudonymizeCPFFormatPreserving("529.982.247-25", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, other.Pseudonym, result.Pseudonym)
+	assert.True(t, isSyntheticCPF(result.Pseudonym))
+
+	// A nil source keeps crypto/rand
+	svc := NewService(key, WithRandomSource(nil))
+	ciphertext, err = svc.Encrypt("52998224725")
+	assert.NoError(t, err)
+	assert.NotEqual(t, expected, ciphertext)
+
+	// An exhausted source fails instead of reusing a nonce
+	svc = NewService(key, WithRandomSource(bytes.NewReader(seed[:4])))
+	_, err = svc.Encrypt("52998224725")
+	assert.Error(t, err)
+}
+
+func FuzzDecrypt(f *testing.F) {
+	const canary = "52998224725"
+
+	keyring, err := NewServiceWithKeyring(map[int][]byte{1: randomKey(f, 32), 2: randomKey(f, 32)}, 2, WithEnvelopeFormat())
+	assert.NoError(f, err)
+	siv, err := NewServiceWithMode(randomKey(f, 64), ModeSIV)
+	assert.NoError(f, err)
+	chacha, err := NewServiceWithMode(randomKey(f, 32), ModeChaCha20Poly1305)
+	assert.NoError(f, err)
+	services := []*Service{NewService(randomKey(f, 32)), keyring, siv, chacha}
+
+	// Valid ciphertexts of the canary, and inputs around the size boundaries
+	for _, svc := range services {
+		encrypted, err := svc.encrypt(canary)
+		assert.NoError(f, err)
+		f.Add(encrypted)
+		data, err := decodeCiphertext(encrypted)
+		assert.NoError(f, err)
+		for _, size := range []int{0, 1, 2, 12, 13, 16, 24, 28, 29, len(data) - 1} {
+			f.Add(base64.StdEncoding.EncodeToString(data[:min(size, len(data))]))
+		}
+	}
+	f.Add("")
+	f.Add("not base64!")
+	f.Add("AQ==")
+	f.Add("AQE=")
+
+	f.Fuzz(func(t *testing.T, ciphertext string) {
+		for _, svc := range services {
+			plaintext, err := svc.decrypt(ciphertext)
+			if err != nil {
+				assert.Empty(t, plaintext)
+				continue
+			}
+			// Only ciphertexts the service produced authenticate
+			assert.Equal(t, canary, plaintext)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package pseudonymization
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter bounds how often a Service re-identifies data (see
+// WithRateLimiter). Allow is called once per decryption attempt, before any
+// decryption work, and the attempt fails with ErrRateLimited when it returns
+// false. Implementations must be safe for concurrent use; ctx is the context
+// of the call (context.Background for methods without one), so a limiter can
+// keep separate quotas per caller identified by a context value.
+type RateLimiter interface {
+	Allow(ctx context.Context) bool
+}
+
+// TokenBucketLimiter is an in-memory RateLimiter shared by all callers: a
+// bucket of up to limit tokens, refilled continuously at limit tokens per
+// interval, where every attempt takes one token. A burst of limit attempts
+// is allowed after a quiet period, and the sustained rate never exceeds
+// limit per interval. Its state is per process: services spread over many
+// processes need a shared store to enforce a global quota.
+type TokenBucketLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	perToken time.Duration
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewTokenBucketLimiter creates a full TokenBucketLimiter allowing limit
+// attempts per interval
+//
+// Parameters:
+// - limit: Attempts allowed per interval, and the largest burst; at least 1
+// - interval: Length of the window, e.g. time.Minute
+//
+// Returns:
+// - The limiter; it panics if limit or interval is not positive
+func NewTokenBucketLimiter(limit int, interval time.Duration) *TokenBucketLimiter {
+	if limit <= 0 || interval <= 0 {
+		panic("pseudonymization: token bucket limit and interval must be positive")
+	}
+	return &TokenBucketLimiter{
+		capacity: float64(limit),
+		perToken: interval / time.Duration(limit),
+		tokens:   float64(limit),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Allow takes a token from the bucket, reporting false if it is empty
+func (l *TokenBucketLimiter) Allow(context.Context) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.capacity, l.tokens+float64(elapsed)/float64(l.perToken))
+		l.last = now
+	}
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// allowRevert consults the configured RateLimiter, if any, before a
+// decryption attempt
+func (s *Service) allowRevert(ctx context.Context) error {
+	if s.rateLimiter == nil || s.rateLimiter.Allow(ctx) {
+		return nil
+	}
+	return ErrRateLimited
+}
@@ -0,0 +1,76 @@
+package pseudonymization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewTokenBucketLimiter(3, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limiter.last = now
+	ctx := context.Background()
+
+	// A full bucket allows a burst of limit attempts
+	for range 3 {
+		assert.True(t, limiter.Allow(ctx))
+	}
+	assert.False(t, limiter.Allow(ctx))
+
+	// One token comes back every interval/limit
+	now = now.Add(19 * time.Second)
+	assert.False(t, limiter.Allow(ctx))
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Allow(ctx))
+	assert.False(t, limiter.Allow(ctx))
+
+	// A long pause refills the bucket, but never beyond its capacity
+	now = now.Add(time.Hour)
+	for range 3 {
+		assert.True(t, limiter.Allow(ctx))
+	}
+	assert.False(t, limiter.Allow(ctx))
+
+	assert.Panics(t, func() { NewTokenBucketLimiter(0, time.Minute) })
+	assert.Panics(t, func() { NewTokenBucketLimiter(1, 0) })
+}
+
+func TestWithRateLimiter(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithRateLimiter(NewTokenBucketLimiter(2, time.Hour)), WithAuditLogger(logger))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	// Failed attempts count against the quota
+	_, err = svc.Revert("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	_, err = svc.RevertDetailed(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	_, _, err = svc.RevertAny(result.EncryptedValue, [][]byte{randomKey(t, 32)})
+	assert.ErrorIs(t, err, ErrRateLimited)
+	_, errs := svc.RevertBatch([]string{result.EncryptedValue})
+	assert.ErrorIs(t, errs[0], ErrRateLimited)
+
+	// Denied attempts are not audited as re-identifications
+	reverts := 0
+	for _, event := range logger.events {
+		if event.Operation == OperationRevert {
+			reverts++
+		}
+	}
+	assert.Equal(t, 1, reverts)
+
+	// Key management paths are not limited
+	_, err = svc.Decrypt(result.EncryptedValue)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,62 @@
+package pseudonymization
+
+import (
+	"context"
+	"time"
+)
+
+// Repseudonymize replaces a pseudonymized value that the data subject has
+// corrected (LGPD art. 18, III; GDPR art. 16 right to rectification). The old
+// encrypted value is decrypted only to hash it, then newValue is
+// pseudonymized as by Pseudonymize, with a fresh pseudonym and ciphertext.
+//
+// Besides the usual OperationPseudonymize event for the new value, an
+// OperationRectify event links the hash of the old value (PreviousHash) to
+// the hash and pseudonym of the new one, so the audit trail shows which
+// record was rectified without keeping either value. The old plaintext is
+// zeroed before returning and is not audited as a re-identification.
+//
+// Parameters:
+//   - oldEncrypted: Base64-encoded encrypted value being replaced; with
+//     WithPurposeBinding it must have been produced for purpose and system
+//   - newValue: The corrected sensitive value
+//   - purpose: Reason for pseudonymization (for audit trails)
+//   - system: Originating system (for audit trails)
+//
+// Returns:
+//   - Result for newValue
+//   - error: ErrNotReversible for an empty oldEncrypted, any error of
+//     RevertWithContext for the old value or of Pseudonymize for the new one
+func (s *Service) Repseudonymize(oldEncrypted, newValue, purpose, system string) (_ *Result, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if oldEncrypted == "" {
+		return nil, ErrNotReversible
+	}
+
+	old, err := s.decryptBytesWithAAD(oldEncrypted, s.additionalData(purpose, system, nil))
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(old)
+	previousHash, err := s.originalHash(string(old))
+	if err != nil {
+		return nil, err
+	}
+
+	// Unobserved, so the rectification is reported once
+	result, err := s.pseudonymizeUnobserved(context.Background(), newValue, PseudonymizeOptions{Purpose: purpose, System: system})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(context.Background(), AuditEvent{
+		Operation:    OperationRectify,
+		OriginalHash: result.OriginalHash,
+		PreviousHash: previousHash,
+		Pseudonym:    result.Pseudonym,
+		Purpose:      purpose,
+		System:       system,
+	})
+	return result, nil
+}
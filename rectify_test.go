@@ -0,0 +1,45 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepseudonymize(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithAuditLogger(logger), WithPurposeBinding())
+
+	old, err := svc.Pseudonymize("Jose da Silva", "crm", "web")
+	assert.NoError(t, err)
+
+	rectified, err := svc.Repseudonymize(old.EncryptedValue, "José da Silva", "crm", "web")
+	assert.NoError(t, err)
+	assert.NotEqual(t, old.Pseudonym, rectified.Pseudonym)
+	assert.Equal(t, svc.Hash("José da Silva"), rectified.OriginalHash)
+
+	original, err := svc.RevertWithContext(rectified.EncryptedValue, "crm", "web")
+	assert.NoError(t, err)
+	assert.Equal(t, "José da Silva", original)
+
+	// Pseudonymize, then pseudonymize and rectify, then revert
+	assert.Len(t, logger.events, 4)
+	assert.Equal(t, OperationPseudonymize, logger.events[1].Operation)
+	event := logger.events[2]
+	assert.Equal(t, OperationRectify, event.Operation)
+	assert.Equal(t, old.OriginalHash, event.PreviousHash)
+	assert.Equal(t, rectified.OriginalHash, event.OriginalHash)
+	assert.Equal(t, rectified.Pseudonym, event.Pseudonym)
+	assert.Equal(t, "crm", event.Purpose)
+	assert.Equal(t, "web", event.System)
+
+	// The old value must decrypt under the same purpose and system
+	_, err = svc.Repseudonymize(old.EncryptedValue, "José da Silva", "marketing", "web")
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = svc.Repseudonymize("", "José da Silva", "crm", "web")
+	assert.ErrorIs(t, err, ErrNotReversible)
+	_, err = svc.Repseudonymize(old.EncryptedValue, "", "crm", "web")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+	assert.Len(t, logger.events, 4)
+}
@@ -0,0 +1,71 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplianceReport(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	svc := NewService(randomKey(t, 32),
+		WithAuditLogger(NewJSONAuditLogger(&buf)),
+		WithClock(func() time.Time { return now }))
+
+	for i := 0; i < 3; i++ {
+		result, err := svc.Pseudonymize("52998224725", "billing", "erp")
+		assert.NoError(t, err)
+		if i == 0 {
+			_, err = svc.RevertWithContext(result.EncryptedValue, "court-order", "legal")
+			assert.NoError(t, err)
+		}
+	}
+	_, err := svc.Pseudonymize("user@example.com", "marketing", "")
+	assert.NoError(t, err)
+
+	// An event outside the window
+	now = now.Add(48 * time.Hour)
+	_, err = svc.Pseudonymize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+
+	events, err := ReadJSONAuditLog(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, events, 6)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	report := NewComplianceReport(events, from, from.Add(24*time.Hour))
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, map[Operation]int{OperationPseudonymize: 4, OperationRevert: 1}, report.ByOperation)
+	assert.Equal(t, map[string]int{"billing": 3, "court-order": 1, "marketing": 1}, report.ByPurpose)
+	assert.Equal(t, map[string]int{"erp": 3, "legal": 1, "": 1}, report.BySystem)
+	assert.Equal(t, map[string]int{"court-order": 1}, report.ReidentificationsByPurpose)
+
+	data, err := json.Marshal(report)
+	assert.NoError(t, err)
+	var decoded ComplianceReport
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *report, decoded)
+
+	var table strings.Builder
+	assert.NoError(t, report.WriteTable(&table))
+	assert.Regexp(t, `Total events +5\n`, table.String())
+	assert.Regexp(t, `\nbilling +3\n`, table.String())
+	assert.Contains(t, table.String(), "(none)")
+	assert.Less(t, strings.Index(table.String(), "pseudonymize"), strings.Index(table.String(), "revert"))
+}
+
+func TestReadJSONAuditLog(t *testing.T) {
+	events, err := ReadJSONAuditLog(strings.NewReader("{\"operation\":\"revert\",\"timestamp\":\"2024-03-01T12:00:00Z\"}\n\n"))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, OperationRevert, events[0].Operation)
+
+	_, err = ReadJSONAuditLog(strings.NewReader("{}\nnot json\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
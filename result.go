@@ -0,0 +1,291 @@
+package pseudonymization
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSONOption configures how Result.ToJSON serializes a Result
+type JSONOption func(*jsonConfig)
+
+type jsonConfig struct {
+	rfc3339 bool
+}
+
+// WithRFC3339Timestamp makes ToJSON emit the timestamp as an ISO-8601 /
+// RFC 3339 string (e.g. "2025-01-02T15:04:05Z") instead of Unix seconds,
+// which is easier to read in logs
+func WithRFC3339Timestamp() JSONOption {
+	return func(c *jsonConfig) {
+		c.rfc3339 = true
+	}
+}
+
+// resultAlias has the fields of Result but none of its methods, so it can be
+// marshaled without recursing into Result's own JSON methods
+type resultAlias Result
+
+// resultJSON overrides the timestamp representation of a Result
+type resultJSON struct {
+	*resultAlias
+	Timestamp interface{} `json:"anonymization_at"`
+}
+
+// ToJSON serializes the Result using its JSON field names
+//
+// Parameters:
+// - opts: optional settings such as WithRFC3339Timestamp
+//
+// Returns:
+// - JSON encoding of the Result
+// - error if encoding fails
+func (r *Result) ToJSON(opts ...JSONOption) ([]byte, error) {
+	var cfg jsonConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	out := resultJSON{resultAlias: (*resultAlias)(r), Timestamp: r.Timestamp}
+	if cfg.rfc3339 {
+		out.Timestamp = time.Unix(r.Timestamp, 0).UTC().Format(time.RFC3339)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a Result whose timestamp is either Unix seconds or an
+// RFC 3339 string
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var in struct {
+		*resultAlias
+		Timestamp json.RawMessage `json:"anonymization_at"`
+	}
+	in.resultAlias = (*resultAlias)(r)
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	timestamp, err := parseTimestamp(in.Timestamp)
+	if err != nil {
+		return err
+	}
+	r.Timestamp = timestamp
+	return nil
+}
+
+// ResultFromJSON decodes a Result produced by ToJSON (with either timestamp
+// format) or by encoding/json, and checks that its pseudonym is well formed
+// (see PseudonymizeWith)
+//
+// Parameters:
+// - data: JSON encoding of a Result
+//
+// Returns:
+// - Decoded Result
+// - error wrapping ErrInvalidResult if the JSON or the pseudonym is malformed
+func ResultFromJSON(data []byte) (*Result, error) {
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResult, err)
+	}
+
+	if !validPseudonym(r.Pseudonym) {
+		return nil, fmt.Errorf("%w: malformed pseudonym %q", ErrInvalidResult, r.Pseudonym)
+	}
+	return &r, nil
+}
+
+// minCiphertextSize is the smallest decoded EncryptedValue accepted by
+// Validate: the size of an AES-GCM nonce
+const minCiphertextSize = 12
+
+// Validate checks the internal consistency of a Result read from storage, so
+// corrupted or tampered records are caught before they reach Revert:
+//   - Pseudonym is well formed: non-empty, without whitespace or control
+//     characters
+//   - OriginalHash is a 32 or 64-byte digest (see HashAlgorithm) in one of
+//     the hash encodings (see HashEncoding)
+//   - EncryptedValue is base64 (standard or URL-safe) of at least a
+//     nonce's length, or empty for a Result produced by Anonymize
+//   - HashSalt, when present, is hex encoded
+//   - Timestamp is not negative (zero when omitted, see
+//     PseudonymizeOptions.OmitTimestamp)
+//   - ExpiresAt, when set, is after Timestamp
+//
+// Validate does not decrypt anything, so it cannot tell whether the
+// ciphertext authenticates under the service key.
+//
+// Returns:
+// - nil if the Result is consistent
+// - error joining one error per problem, each wrapping ErrInvalidResult
+func (r *Result) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidResult}, args...)...))
+	}
+
+	if !validPseudonym(r.Pseudonym) {
+		invalid("malformed pseudonym %q", r.Pseudonym)
+	}
+	if !validHash(r.OriginalHash) {
+		invalid("original hash must be a %d or %d-byte digest in hex, base64 or base32", sha256Size, sha512Size)
+	}
+	if r.EncryptedValue != "" {
+		ciphertext, err := decodeCiphertext(r.EncryptedValue)
+		if err != nil {
+			invalid("encrypted value is not valid base64")
+		} else if len(ciphertext) < minCiphertextSize {
+			invalid("encrypted value is %d bytes, want at least %d", len(ciphertext), minCiphertextSize)
+		}
+	}
+	if _, err := hex.DecodeString(r.HashSalt); err != nil {
+		invalid("hash salt is not valid hex")
+	}
+	if r.Timestamp < 0 {
+		invalid("timestamp must not be negative, got %d", r.Timestamp)
+	}
+	if r.ExpiresAt != 0 && r.ExpiresAt <= r.Timestamp {
+		invalid("expiry %d is not after timestamp %d", r.ExpiresAt, r.Timestamp)
+	}
+	return errors.Join(errs...)
+}
+
+// IsExpired reports whether the retention period of the Result has passed at
+// now. A Result without ExpiresAt never expires.
+func (r *Result) IsExpired(now time.Time) bool {
+	return r.ExpiresAt != 0 && !now.Before(time.Unix(r.ExpiresAt, 0))
+}
+
+// fingerprintLength is the number of hex characters of OriginalHash kept by
+// Fingerprint
+const fingerprintLength = 8
+
+// Fingerprint returns the first 8 hex characters of OriginalHash, a short ID
+// for correlating records in logs and dashboards without showing the full
+// hash. It is a display convenience only: 32 bits collide after roughly
+// 65,000 distinct values, so fingerprints must never be used as keys or for
+// equality checks. A shorter OriginalHash is returned whole.
+func (r *Result) Fingerprint() string {
+	if len(r.OriginalHash) <= fingerprintLength {
+		return r.OriginalHash
+	}
+	return r.OriginalHash[:fingerprintLength]
+}
+
+// Redacted returns a copy of the Result with EncryptedValue blanked, for
+// logging or handing to systems that must not hold the ciphertext. The hash,
+// pseudonym and metadata are kept, since they do not reveal the original
+// value.
+func (r *Result) Redacted() *Result {
+	redacted := *r
+	redacted.EncryptedValue = ""
+	redacted.Metadata = maps.Clone(r.Metadata)
+	return &redacted
+}
+
+// String formats the Result for logs and debugging. EncryptedValue is always
+// omitted, so printing a Result with fmt cannot leak the ciphertext.
+func (r Result) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Result{pseudonym=%s hash=%s timestamp=%d", r.Pseudonym, r.OriginalHash, r.Timestamp)
+	if r.ExpiresAt != 0 {
+		fmt.Fprintf(&b, " expires_at=%d", r.ExpiresAt)
+	}
+	if r.HashSalt != "" {
+		fmt.Fprintf(&b, " hash_salt=%s", r.HashSalt)
+	}
+	if r.PIIType != "" {
+		fmt.Fprintf(&b, " pii_type=%s", r.PIIType)
+	}
+	if len(r.Metadata) > 0 {
+		keys := make([]string, 0, len(r.Metadata))
+		for key := range r.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		b.WriteString(" metadata=map[")
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "%s:%s", key, r.Metadata[key])
+		}
+		b.WriteByte(']')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// LogValue implements slog.LogValuer, logging the Result as a group keyed by
+// its JSON field names. Like String, it omits EncryptedValue.
+func (r Result) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("client_id", r.Pseudonym),
+		slog.String("original_hash_value", r.OriginalHash),
+		slog.Int64("anonymization_at", r.Timestamp),
+	}
+	if r.ExpiresAt != 0 {
+		attrs = append(attrs, slog.Int64("expires_at", r.ExpiresAt))
+	}
+	if r.HashSalt != "" {
+		attrs = append(attrs, slog.String("hash_salt", r.HashSalt))
+	}
+	if r.PIIType != "" {
+		attrs = append(attrs, slog.String("pii_type", string(r.PIIType)))
+	}
+	if len(r.Metadata) > 0 {
+		attrs = append(attrs, slog.Any("metadata", r.Metadata))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// parseTimestamp decodes a JSON timestamp given as Unix seconds or RFC 3339
+func parseTimestamp(raw json.RawMessage) (int64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var seconds int64
+	if err := json.Unmarshal(raw, &seconds); err == nil {
+		return seconds, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return 0, fmt.Errorf("%w: timestamp must be Unix seconds or an RFC 3339 string", ErrInvalidResult)
+	}
+	parsed, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidResult, err)
+	}
+	return parsed.Unix(), nil
+}
+
+// maxPseudonymLength bounds the size of caller-supplied pseudonyms
+const maxPseudonymLength = 255
+
+// validPseudonym reports whether pseudonym is acceptable as Result.Pseudonym.
+// Besides the UUIDs and synthetic CPFs this package generates, callers may
+// supply their own identifiers with PseudonymizeWith, so any non-empty UTF-8
+// string of up to maxPseudonymLength bytes without whitespace or control
+// characters is accepted.
+func validPseudonym(pseudonym string) bool {
+	if pseudonym == "" || len(pseudonym) > maxPseudonymLength || !utf8.ValidString(pseudonym) {
+		return false
+	}
+	for _, r := range pseudonym {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,236 @@
+package pseudonymization
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// resultBinaryVersion is the first byte of every binary-encoded Result
+const resultBinaryVersion = 1
+
+// Digest sizes of the supported hash algorithms: 32 bytes for SHA-256 and
+// SHA3-256, 64 bytes for SHA-512 and BLAKE2b-512
+const (
+	sha256Size = 32
+	sha512Size = 64
+)
+
+// Flags describing how each field of a binary-encoded Result is stored. Fields
+// in their canonical form (lowercase hex hash, UUID pseudonym, standard base64
+// ciphertext) are stored as raw bytes; anything else is stored verbatim as a
+// length-prefixed string, so every Result round-trips exactly.
+const (
+	binaryRawHash       byte = 1 << iota // hash as 32 raw bytes
+	binaryUUIDPseudonym                  // pseudonym as 16 raw bytes
+	binaryRawCiphertext                  // ciphertext as length-prefixed raw bytes
+	binaryMetadata                       // metadata entries follow the timestamp
+	binaryHashSalt                       // length-prefixed salt follows the hash
+	binaryExpiresAt                      // varint expiry follows the timestamp
+	binaryPIIType                        // length-prefixed PII type follows the expiry
+)
+
+// MarshalBinary encodes the Result in a compact binary layout, avoiding the
+// hex and base64 overhead of the JSON form:
+//
+//	version (1) | flags (1) | hash (32) | [uvarint length + salt] | pseudonym (16) |
+//	uvarint length + ciphertext | varint timestamp | [varint expiry] |
+//	[uvarint length + PII type] |
+//	[uvarint count + (uvarint length + key, uvarint length + value)...]
+//
+// Together with UnmarshalBinary it also makes Result efficient to send with
+// encoding/gob.
+func (r *Result) MarshalBinary() ([]byte, error) {
+	var flags byte
+	out := make([]byte, 2, 2+sha256Size+16+len(r.EncryptedValue)+2*binary.MaxVarintLen64)
+
+	if hash, err := hex.DecodeString(r.OriginalHash); err == nil && len(hash) == sha256Size && hex.EncodeToString(hash) == r.OriginalHash {
+		flags |= binaryRawHash
+		out = append(out, hash...)
+	} else {
+		out = appendBinaryString(out, r.OriginalHash)
+	}
+	if r.HashSalt != "" {
+		flags |= binaryHashSalt
+		out = appendBinaryString(out, r.HashSalt)
+	}
+
+	if id, err := uuid.Parse(r.Pseudonym); err == nil && id.String() == r.Pseudonym {
+		flags |= binaryUUIDPseudonym
+		out = append(out, id[:]...)
+	} else {
+		out = appendBinaryString(out, r.Pseudonym)
+	}
+
+	if ciphertext, err := base64.StdEncoding.DecodeString(r.EncryptedValue); err == nil && base64.StdEncoding.EncodeToString(ciphertext) == r.EncryptedValue {
+		flags |= binaryRawCiphertext
+		out = appendBinaryString(out, string(ciphertext))
+	} else {
+		out = appendBinaryString(out, r.EncryptedValue)
+	}
+
+	out = appendVarint(out, r.Timestamp)
+	if r.ExpiresAt != 0 {
+		flags |= binaryExpiresAt
+		out = appendVarint(out, r.ExpiresAt)
+	}
+	if r.PIIType != "" {
+		flags |= binaryPIIType
+		out = appendBinaryString(out, string(r.PIIType))
+	}
+
+	if len(r.Metadata) > 0 {
+		flags |= binaryMetadata
+		keys := make([]string, 0, len(r.Metadata))
+		for key := range r.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		out = appendUvarint(out, uint64(len(keys)))
+		for _, key := range keys {
+			out = appendBinaryString(out, key)
+			out = appendBinaryString(out, r.Metadata[key])
+		}
+	}
+
+	out[0], out[1] = resultBinaryVersion, flags
+	return out, nil
+}
+
+// UnmarshalBinary decodes a Result produced by MarshalBinary
+func (r *Result) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || data[0] != resultBinaryVersion {
+		return fmt.Errorf("%w: unsupported binary encoding", ErrInvalidResult)
+	}
+	d := binaryDecoder{data: data[2:]}
+	flags := data[1]
+
+	var decoded Result
+	if flags&binaryRawHash != 0 {
+		decoded.OriginalHash = hex.EncodeToString(d.bytes(sha256Size))
+	} else {
+		decoded.OriginalHash = d.string()
+	}
+	if flags&binaryHashSalt != 0 {
+		decoded.HashSalt = d.string()
+	}
+
+	if flags&binaryUUIDPseudonym != 0 {
+		var id uuid.UUID
+		copy(id[:], d.bytes(len(id)))
+		decoded.Pseudonym = id.String()
+	} else {
+		decoded.Pseudonym = d.string()
+	}
+
+	if flags&binaryRawCiphertext != 0 {
+		decoded.EncryptedValue = base64.StdEncoding.EncodeToString([]byte(d.string()))
+	} else {
+		decoded.EncryptedValue = d.string()
+	}
+
+	decoded.Timestamp = d.varint()
+	if flags&binaryExpiresAt != 0 {
+		decoded.ExpiresAt = d.varint()
+	}
+	if flags&binaryPIIType != 0 {
+		decoded.PIIType = PIIType(d.string())
+	}
+
+	if flags&binaryMetadata != 0 {
+		count := d.uvarint()
+		if count > uint64(len(d.data)) {
+			d.fail()
+		}
+		decoded.Metadata = make(map[string]string, int(count))
+		for i := uint64(0); i < count && d.err == nil; i++ {
+			key := d.string()
+			decoded.Metadata[key] = d.string()
+		}
+	}
+
+	if d.err == nil && len(d.data) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidResult, len(d.data))
+	}
+	if d.err != nil {
+		return d.err
+	}
+	*r = decoded
+	return nil
+}
+
+// appendBinaryString appends s prefixed with its uvarint length
+func appendBinaryString(out []byte, s string) []byte {
+	out = appendUvarint(out, uint64(len(s)))
+	return append(out, s...)
+}
+
+// appendUvarint appends the uvarint encoding of v
+func appendUvarint(out []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(out, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+// appendVarint appends the varint encoding of v
+func appendVarint(out []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(out, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+// binaryDecoder reads the fields of a binary-encoded Result, recording the
+// first error and returning zero values after it
+type binaryDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *binaryDecoder) fail() {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: truncated binary encoding", ErrInvalidResult)
+	}
+	d.data = nil
+}
+
+func (d *binaryDecoder) bytes(n int) []byte {
+	if d.err != nil || len(d.data) < n {
+		d.fail()
+		return make([]byte, n)
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if d.err != nil || n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *binaryDecoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if d.err != nil || n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *binaryDecoder) string() string {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail()
+		return ""
+	}
+	return string(d.bytes(int(n)))
+}
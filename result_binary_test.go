@@ -0,0 +1,82 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultBinaryRoundTrip(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	email, err := svc.PseudonymizeEmail("maria@example.com", "test", "test")
+	assert.NoError(t, err)
+	cpf, err := svc.PseudonymizeCPFFormatPreserving("529.982.247-25", "test", "test")
+	assert.NoError(t, err)
+	expiring, err := svc.PseudonymizeWithTTL("52998224725", "test", "test", time.Hour)
+	assert.NoError(t, err)
+
+	for _, original := range []*Result{
+		result,
+		email,
+		cpf,
+		expiring,
+		{},
+		{OriginalHash: "ABC", Pseudonym: "custom", EncryptedValue: "not base64!", Timestamp: -1},
+		{Pseudonym: "typed", PIIType: PIITypeEmail, Metadata: map[string]string{"domain": "example.com"}},
+	} {
+		data, err := original.MarshalBinary()
+		assert.NoError(t, err)
+
+		var decoded Result
+		assert.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, *original, decoded)
+	}
+}
+
+func TestResultBinarySize(t *testing.T) {
+	result, err := NewService(randomKey(t, 32)).Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	binaryData, err := result.MarshalBinary()
+	assert.NoError(t, err)
+	jsonData, err := result.ToJSON()
+	assert.NoError(t, err)
+
+	// version, flags, hash, UUID, length + 39-byte ciphertext, 5-byte timestamp
+	assert.Len(t, binaryData, 2+32+16+1+39+5)
+	assert.Less(t, len(binaryData)*2, len(jsonData))
+	t.Logf("binary: %d bytes, JSON: %d bytes", len(binaryData), len(jsonData))
+}
+
+func TestResultGob(t *testing.T) {
+	result, err := NewService(randomKey(t, 32)).PseudonymizeEmail("maria@example.com", "test", "test")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(result))
+
+	var decoded Result
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	assert.Equal(t, *result, decoded)
+}
+
+func TestResultUnmarshalBinaryInvalid(t *testing.T) {
+	result, err := NewService(randomKey(t, 32)).PseudonymizeEmail("maria@example.com", "test", "test")
+	assert.NoError(t, err)
+	data, err := result.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded Result
+	for i := 0; i < len(data); i++ {
+		assert.ErrorIs(t, decoded.UnmarshalBinary(data[:i]), ErrInvalidResult, "truncated at %d", i)
+	}
+	assert.ErrorIs(t, decoded.UnmarshalBinary(append(data, 0)), ErrInvalidResult)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(append([]byte{2}, data[1:]...)), ErrInvalidResult)
+	assert.Equal(t, Result{}, decoded)
+}
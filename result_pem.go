@@ -0,0 +1,63 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+)
+
+// DefaultPEMLabel is the PEM block type used by EncryptedValuePEM when no
+// label is given
+const DefaultPEMLabel = "PSEUDONYMIZED VALUE"
+
+// EncryptedValuePEM wraps EncryptedValue in a PEM block (RFC 7468): the
+// ciphertext in standard base64, wrapped at 64 columns, between BEGIN and END
+// lines naming label. It suits tools and people that handle encrypted blobs
+// as PEM; ParseEncryptedValuePEM reverses it. The block holds only the
+// ciphertext, not the hash, pseudonym or any other field.
+//
+// Parameters:
+// - label: PEM block type, e.g. "ENCRYPTED CPF"; empty uses DefaultPEMLabel
+//
+// Returns:
+//   - The PEM block, ending with a newline, or an empty string if
+//     EncryptedValue is empty (as in a Result from Anonymize) or not base64
+func (r *Result) EncryptedValuePEM(label string) string {
+	if r.EncryptedValue == "" {
+		return ""
+	}
+	ciphertext, err := decodeCiphertext(r.EncryptedValue)
+	if err != nil {
+		return ""
+	}
+	if label == "" {
+		label = DefaultPEMLabel
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: label, Bytes: ciphertext}))
+}
+
+// ParseEncryptedValuePEM extracts the encrypted value from a PEM block written
+// by EncryptedValuePEM, whatever its label. Text around the block is
+// ignored, but there must be exactly one block.
+//
+// Parameters:
+// - encoded: The PEM-encoded encrypted value
+//
+// Returns:
+//   - The encrypted value in standard base64, as accepted by Revert
+//   - error wrapping ErrMalformedCiphertext if encoded holds no PEM block, an
+//     empty one or more than one
+func ParseEncryptedValuePEM(encoded string) (string, error) {
+	block, rest := pem.Decode([]byte(encoded))
+	if block == nil {
+		return "", fmt.Errorf("%w: no PEM block found", ErrMalformedCiphertext)
+	}
+	if len(block.Bytes) == 0 {
+		return "", fmt.Errorf("%w: empty PEM block", ErrMalformedCiphertext)
+	}
+	if next, _ := pem.Decode(rest); next != nil || bytes.Contains(rest, []byte("-----BEGIN")) {
+		return "", fmt.Errorf("%w: more than one PEM block", ErrMalformedCiphertext)
+	}
+	return base64.StdEncoding.EncodeToString(block.Bytes), nil
+}
@@ -0,0 +1,54 @@
+package pseudonymization
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedValuePEM(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithCiphertextEncoding(base64.RawURLEncoding))
+	result, err := svc.Pseudonymize(strings.Repeat("52998224725", 10), "test", "test")
+	assert.NoError(t, err)
+
+	block := result.EncryptedValuePEM("ENCRYPTED CPF")
+	assert.True(t, strings.HasPrefix(block, "-----BEGIN ENCRYPTED CPF-----\n"))
+	assert.True(t, strings.HasSuffix(block, "-----END ENCRYPTED CPF-----\n"))
+	for _, line := range strings.Split(strings.TrimSpace(block), "\n") {
+		assert.LessOrEqual(t, len(line), 64)
+	}
+	assert.Contains(t, result.EncryptedValuePEM(""), "-----BEGIN "+DefaultPEMLabel+"-----")
+
+	// The parsed value reverts, even surrounded by other text
+	encrypted, err := ParseEncryptedValuePEM("Attached:\n\n" + block + "\nRegards")
+	assert.NoError(t, err)
+	original, err := svc.Revert(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("52998224725", 10), original)
+
+	// Results without a ciphertext have nothing to wrap
+	assert.Empty(t, result.Redacted().EncryptedValuePEM(""))
+	assert.Empty(t, (&Result{EncryptedValue: "not base64!"}).EncryptedValuePEM(""))
+}
+
+func TestParseEncryptedValuePEM(t *testing.T) {
+	testCases := []struct {
+		name    string
+		encoded string
+	}{
+		{"empty", ""},
+		{"no block", "AAAAAAAAAAAAAAAA"},
+		{"empty block", "-----BEGIN X-----\n-----END X-----\n"},
+		{"two blocks", "-----BEGIN X-----\nAAAA\n-----END X-----\n-----BEGIN Y-----\nAAAA\n-----END Y-----\n"},
+		{"bad base64", "-----BEGIN X-----\n!!!!\n-----END X-----\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseEncryptedValuePEM(tc.encoded)
+			assert.ErrorIs(t, err, ErrMalformedCiphertext)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package pseudonymization
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value implements driver.Valuer, so a Result can be stored directly in a
+// database/sql column. It is stored as its JSON encoding (see ToJSON), which
+// fits TEXT, JSON and JSONB columns; a nil *Result is stored as NULL.
+func (r Result) Value() (driver.Value, error) {
+	return r.ToJSON()
+}
+
+// Scan implements sql.Scanner. It accepts the JSON written by Value as well
+// as the binary form of MarshalBinary, so BLOB/BYTEA columns filled with
+// MarshalBinary can be read too. NULL leaves the Result zeroed.
+func (r *Result) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*r = Result{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into Result", ErrInvalidResult, src)
+	}
+
+	if len(data) > 0 && data[0] == resultBinaryVersion {
+		return r.UnmarshalBinary(data)
+	}
+
+	var decoded Result
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResult, err)
+	}
+	*r = decoded
+	return nil
+}
@@ -0,0 +1,122 @@
+package pseudonymization
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDriver is a minimal database/sql driver storing a single column:
+// "INSERT" stores its argument and "SELECT" returns the stored value
+type mockDriver struct {
+	mu    sync.Mutex
+	value driver.Value
+}
+
+func (d *mockDriver) Open(string) (driver.Conn, error) { return &mockConn{d: d}, nil }
+
+type mockConn struct{ d *mockDriver }
+
+func (c *mockConn) Prepare(query string) (driver.Stmt, error) {
+	return &mockStmt{d: c.d, query: query}, nil
+}
+func (c *mockConn) Close() error              { return nil }
+func (c *mockConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type mockStmt struct {
+	d     *mockDriver
+	query string
+}
+
+func (s *mockStmt) Close() error { return nil }
+func (s *mockStmt) NumInput() int {
+	if s.query == "INSERT" {
+		return 1
+	}
+	return 0
+}
+func (s *mockStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.value = args[0]
+	return driver.RowsAffected(1), nil
+}
+func (s *mockStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	return &mockRows{value: s.d.value}, nil
+}
+
+type mockRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *mockRows) Columns() []string { return []string{"result"} }
+func (r *mockRows) Close() error      { return nil }
+func (r *mockRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+var mockDB = &mockDriver{}
+
+func init() {
+	sql.Register("pseudonymization-mock", mockDB)
+}
+
+func TestResultSQL(t *testing.T) {
+	db, err := sql.Open("pseudonymization-mock", "")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	result, err := NewService(randomKey(t, 32)).PseudonymizeEmail("maria@example.com", "test", "test")
+	assert.NoError(t, err)
+
+	// Stored as JSON
+	_, err = db.Exec("INSERT", result)
+	assert.NoError(t, err)
+	stored, ok := mockDB.value.([]byte)
+	assert.True(t, ok)
+	assert.Equal(t, byte('{'), stored[0])
+
+	var scanned Result
+	assert.NoError(t, db.QueryRow("SELECT").Scan(&scanned))
+	assert.Equal(t, *result, scanned)
+
+	// Binary form written with MarshalBinary
+	binaryData, err := result.MarshalBinary()
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT", binaryData)
+	assert.NoError(t, err)
+	scanned = Result{}
+	assert.NoError(t, db.QueryRow("SELECT").Scan(&scanned))
+	assert.Equal(t, *result, scanned)
+
+	// NULL leaves the Result zeroed
+	var nilResult *Result
+	_, err = db.Exec("INSERT", nilResult)
+	assert.NoError(t, err)
+	assert.Nil(t, mockDB.value)
+	assert.NoError(t, db.QueryRow("SELECT").Scan(&scanned))
+	assert.Equal(t, Result{}, scanned)
+}
+
+func TestResultScan(t *testing.T) {
+	var r Result
+	assert.NoError(t, r.Scan(`{"client_id":"abc","anonymization_at":"2024-01-02T03:04:05Z"}`))
+	assert.Equal(t, "abc", r.Pseudonym)
+	assert.Equal(t, int64(1704164645), r.Timestamp)
+
+	assert.ErrorIs(t, r.Scan(42), ErrInvalidResult)
+	assert.ErrorIs(t, r.Scan([]byte("not json")), ErrInvalidResult)
+	assert.Equal(t, "abc", r.Pseudonym)
+}
@@ -0,0 +1,148 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultJSONRoundTrip(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.PseudonymizeEmail("user@example.com", "test", "test")
+	assert.NoError(t, err)
+
+	// Unix seconds (default)
+	data, err := result.ToJSON()
+	assert.NoError(t, err)
+	var raw map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, float64(result.Timestamp), raw["anonymization_at"])
+	assert.Equal(t, result.Pseudonym, raw["client_id"])
+
+	decoded, err := ResultFromJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, result, decoded)
+
+	// RFC 3339 timestamp
+	data, err = result.ToJSON(WithRFC3339Timestamp())
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &raw))
+	assert.IsType(t, "", raw["anonymization_at"])
+
+	decoded, err = ResultFromJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, result, decoded)
+
+	// Plain encoding/json output is accepted as well
+	data, err = json.Marshal(result)
+	assert.NoError(t, err)
+	decoded, err = ResultFromJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, result, decoded)
+}
+
+func TestResultRedacted(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.PseudonymizeEmail("user@example.com", "test", "test")
+	assert.NoError(t, err)
+
+	redacted := result.Redacted()
+	assert.Empty(t, redacted.EncryptedValue)
+	assert.NotEmpty(t, result.EncryptedValue)
+	assert.Equal(t, result.Pseudonym, redacted.Pseudonym)
+	assert.Equal(t, result.OriginalHash, redacted.OriginalHash)
+	assert.Equal(t, result.Metadata, redacted.Metadata)
+
+	// The copy does not share metadata with the original
+	redacted.Metadata["domain"] = "changed"
+	assert.Equal(t, "example.com", result.Metadata["domain"])
+
+	// Neither fmt nor slog print the ciphertext, for values and pointers alike
+	for _, printed := range []string{fmt.Sprint(result), fmt.Sprintf("%v", *result), fmt.Sprintf("%+v", result)} {
+		assert.NotContains(t, printed, result.EncryptedValue)
+		assert.Contains(t, printed, result.Pseudonym)
+		assert.Contains(t, printed, result.OriginalHash)
+		assert.Contains(t, printed, "metadata=map[domain:example.com]")
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	logger.Info("pseudonymized", "result", result)
+	assert.NotContains(t, logs.String(), result.EncryptedValue)
+	assert.NotContains(t, logs.String(), "encrypted_original_value")
+
+	var entry struct {
+		Result map[string]interface{} `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, result.Pseudonym, entry.Result["client_id"])
+	assert.Equal(t, result.OriginalHash, entry.Result["original_hash_value"])
+	assert.Equal(t, float64(result.Timestamp), entry.Result["anonymization_at"])
+}
+
+func TestResultFingerprint(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, result.OriginalHash[:8], result.Fingerprint())
+	assert.Len(t, result.Fingerprint(), 8)
+
+	assert.Equal(t, "abc", (&Result{OriginalHash: "abc"}).Fingerprint())
+	assert.Empty(t, (&Result{}).Fingerprint())
+}
+
+func TestResultFromJSONValidation(t *testing.T) {
+	_, err := ResultFromJSON([]byte(`{"client_id": "not a pseudonym", "anonymization_at": 1700000000}`))
+	assert.ErrorIs(t, err, ErrInvalidResult)
+
+	_, err = ResultFromJSON([]byte(`{"client_id": "6f1c1a9e-3f0b-4b8e-9f5e-2a6d1f0c9b7a", "anonymization_at": "yesterday"}`))
+	assert.ErrorIs(t, err, ErrInvalidResult)
+
+	_, err = ResultFromJSON([]byte(`not json`))
+	assert.ErrorIs(t, err, ErrInvalidResult)
+
+	result, err := ResultFromJSON([]byte(`{"client_id": "6f1c1a9e-3f0b-4b8e-9f5e-2a6d1f0c9b7a", "anonymization_at": "2024-05-01T12:00:00Z"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1714564800), result.Timestamp)
+}
+
+func TestResultValidate(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithHMACKey(randomKey(t, 32)))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.NoError(t, result.Validate())
+
+	anonymized, err := svc.Anonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	assert.NoError(t, anonymized.Validate())
+
+	corrupted := *result
+	corrupted.Pseudonym = "not a pseudonym"
+	err = corrupted.Validate()
+	assert.ErrorIs(t, err, ErrInvalidResult)
+	assert.Contains(t, err.Error(), "pseudonym")
+
+	// Every problem is reported, not only the first
+	corrupted = Result{
+		Pseudonym:      "not a pseudonym",
+		OriginalHash:   result.OriginalHash[:63],
+		EncryptedValue: "AAAA",
+		HashSalt:       "zz",
+		Timestamp:      -1,
+	}
+	err = corrupted.Validate()
+	assert.ErrorIs(t, err, ErrInvalidResult)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 5)
+
+	corrupted = *result
+	corrupted.EncryptedValue = "not base64!"
+	assert.ErrorIs(t, corrupted.Validate(), ErrInvalidResult)
+}
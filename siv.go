@@ -0,0 +1,185 @@
+package pseudonymization
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+// sivKeySize is the size of an AES-SIV key: a 32-byte CMAC key followed by a
+// 32-byte CTR key (AES-256-SIV)
+const sivKeySize = 64
+
+// errSIVOpen is returned by sivAEAD.Open when the synthetic IV does not match
+var errSIVOpen = errors.New("message authentication failed")
+
+// sivAEAD implements AES-SIV (RFC 5297) as a cipher.AEAD with a zero-length
+// nonce. The synthetic IV, computed with S2V over the additional data and the
+// plaintext, is prepended to the ciphertext, so encryption is deterministic:
+// the same plaintext and additional data always give the same output.
+type sivAEAD struct {
+	mac cipher.Block // K1, used by S2V
+	ctr cipher.Block // K2, used for CTR encryption
+}
+
+// newSIV builds the AES-SIV cipher for a 64-byte key (see ModeSIV.validateKey)
+func newSIV(key []byte) (cipher.AEAD, error) {
+	half := len(key) / 2
+	mac, err := aes.NewCipher(key[:half])
+	if err != nil {
+		return nil, err
+	}
+	ctr, err := aes.NewCipher(key[half:])
+	if err != nil {
+		return nil, err
+	}
+	return &sivAEAD{mac: mac, ctr: ctr}, nil
+}
+
+// NonceSize returns 0: the IV is derived from the input
+func (c *sivAEAD) NonceSize() int {
+	return 0
+}
+
+// Overhead returns the size of the synthetic IV
+func (c *sivAEAD) Overhead() int {
+	return aes.BlockSize
+}
+
+// Seal appends V || CTR(K2, Q, plaintext) to dst, where V = S2V(K1,
+// additionalData, plaintext) and Q is V with two bits cleared
+func (c *sivAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != 0 {
+		panic("pseudonymization: AES-SIV does not take a nonce")
+	}
+
+	v := c.s2v(additionalData, plaintext)
+	ret, out := sliceForAppend(dst, aes.BlockSize+len(plaintext))
+	copy(out, v[:])
+	c.xorKeyStream(out[aes.BlockSize:], plaintext, v)
+	return ret
+}
+
+// Open decrypts ciphertext produced by Seal and checks its synthetic IV
+func (c *sivAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 0 {
+		panic("pseudonymization: AES-SIV does not take a nonce")
+	}
+	if len(ciphertext) < aes.BlockSize {
+		return nil, errSIVOpen
+	}
+
+	var v [aes.BlockSize]byte
+	copy(v[:], ciphertext)
+	ciphertext = ciphertext[aes.BlockSize:]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	c.xorKeyStream(out, ciphertext, v)
+
+	expected := c.s2v(additionalData, out)
+	if subtle.ConstantTimeCompare(expected[:], v[:]) != 1 {
+		wipe(out)
+		return nil, errSIVOpen
+	}
+	return ret, nil
+}
+
+// xorKeyStream runs AES-CTR under K2 starting from v with the 31st and 63rd
+// bits (counting from the right) cleared, as required by RFC 5297
+func (c *sivAEAD) xorKeyStream(dst, src []byte, v [aes.BlockSize]byte) {
+	v[8] &= 0x7f
+	v[12] &= 0x7f
+	cipher.NewCTR(c.ctr, v[:]).XORKeyStream(dst, src)
+}
+
+// s2v computes the S2V pseudo-random function over the additional data and
+// the plaintext (RFC 5297, section 2.4)
+func (c *sivAEAD) s2v(additionalData, plaintext []byte) [aes.BlockSize]byte {
+	var zero [aes.BlockSize]byte
+	d := cmac(c.mac, zero[:])
+
+	d = dbl(d)
+	xorBlock(&d, cmac(c.mac, additionalData))
+
+	var t []byte
+	if len(plaintext) >= aes.BlockSize {
+		// T = Sn xorend D
+		t = append([]byte(nil), plaintext...)
+		tail := t[len(t)-aes.BlockSize:]
+		for i := range tail {
+			tail[i] ^= d[i]
+		}
+	} else {
+		// T = dbl(D) xor pad(Sn)
+		d = dbl(d)
+		for i, b := range plaintext {
+			d[i] ^= b
+		}
+		d[len(plaintext)] ^= 0x80
+		t = d[:]
+	}
+	return cmac(c.mac, t)
+}
+
+// cmac computes AES-CMAC (RFC 4493) of msg
+func cmac(block cipher.Block, msg []byte) [aes.BlockSize]byte {
+	var l [aes.BlockSize]byte
+	block.Encrypt(l[:], l[:])
+	k1 := dbl(l)
+	k2 := dbl(k1)
+
+	var x [aes.BlockSize]byte
+	for len(msg) > aes.BlockSize {
+		for i := range x {
+			x[i] ^= msg[i]
+		}
+		block.Encrypt(x[:], x[:])
+		msg = msg[aes.BlockSize:]
+	}
+
+	// Last block: complete blocks are masked with K1, partial (or empty)
+	// blocks are padded with 10* and masked with K2
+	if len(msg) == aes.BlockSize {
+		xorBlock(&x, k1)
+	} else {
+		xorBlock(&x, k2)
+		x[len(msg)] ^= 0x80
+	}
+	for i, b := range msg {
+		x[i] ^= b
+	}
+	block.Encrypt(x[:], x[:])
+	return x
+}
+
+// dbl multiplies b by x in GF(2^128)
+func dbl(b [aes.BlockSize]byte) [aes.BlockSize]byte {
+	var out [aes.BlockSize]byte
+	carry := b[0] >> 7
+	for i := 0; i < aes.BlockSize-1; i++ {
+		out[i] = b[i]<<1 | b[i+1]>>7
+	}
+	out[aes.BlockSize-1] = b[aes.BlockSize-1]<<1 ^ 0x87*carry
+	return out
+}
+
+// xorBlock sets dst to dst XOR src
+func xorBlock(dst *[aes.BlockSize]byte, src [aes.BlockSize]byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// sliceForAppend extends in by n bytes, returning the whole slice and the
+// newly added tail
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
@@ -0,0 +1,73 @@
+package pseudonymization
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
+
+func TestCMAC(t *testing.T) {
+	// RFC 4493, section 4
+	block, err := aes.NewCipher(mustHex(t, "2b7e151628aed2a6abf7158809cf4f3c"))
+	assert.NoError(t, err)
+
+	msg := mustHex(t, "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+	testCases := []struct {
+		length int
+		mac    string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+
+	for _, tc := range testCases {
+		mac := cmac(block, msg[:tc.length])
+		assert.Equal(t, tc.mac, hex.EncodeToString(mac[:]), "length %d", tc.length)
+	}
+}
+
+func TestSIV(t *testing.T) {
+	// RFC 5297, appendix A.1 (AES-128-SIV)
+	aead, err := newSIV(mustHex(t, "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff"))
+	assert.NoError(t, err)
+
+	ad := mustHex(t, "101112131415161718191a1b1c1d1e1f2021222324252627")
+	plaintext := mustHex(t, "112233445566778899aabbccddee")
+	expected := "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c"
+
+	sealed := aead.Seal(nil, nil, plaintext, ad)
+	assert.Equal(t, expected, hex.EncodeToString(sealed))
+
+	opened, err := aead.Open(nil, nil, sealed, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	// Wrong additional data or a flipped bit fail authentication
+	_, err = aead.Open(nil, nil, sealed, ad[1:])
+	assert.Error(t, err)
+	sealed[len(sealed)-1] ^= 0x01
+	_, err = aead.Open(nil, nil, sealed, ad)
+	assert.Error(t, err)
+	_, err = aead.Open(nil, nil, sealed[:aes.BlockSize-1], ad)
+	assert.Error(t, err)
+
+	// Plaintexts of at least one block use the xorend branch of S2V
+	long := []byte("a value longer than a single AES block")
+	sealed = aead.Seal(nil, nil, long, nil)
+	opened, err = aead.Open(nil, nil, sealed, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, long, opened)
+
+	assert.Panics(t, func() { aead.Seal(nil, make([]byte, 12), plaintext, nil) })
+}
@@ -0,0 +1,112 @@
+package pseudonymization
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultStatsWindow is the number of most recent latencies per operation
+// that Stats computes percentiles over (see WithStats)
+const DefaultStatsWindow = 1024
+
+// Stats is a snapshot of the operations a Service performed since it was
+// created, returned by Service.Stats
+type Stats struct {
+	Pseudonymize OperationStats // Every pseudonymized value, as seen by Observer
+	Revert       OperationStats // Every reverted value, as seen by Observer
+}
+
+// OperationStats holds the counters and rolling latency percentiles of one
+// kind of operation. Counts cover the lifetime of the Service; percentiles
+// cover only the most recent calls, successful or not, up to the window set
+// with WithStats. Percentiles are zero until the first call.
+type OperationStats struct {
+	Count  uint64 // Calls, including failed ones
+	Errors uint64 // Failed calls
+	P50    time.Duration
+	P95    time.Duration
+	P99    time.Duration
+}
+
+// latencyTracker counts the calls of one operation and keeps their most
+// recent latencies in a ring buffer
+type latencyTracker struct {
+	mu      sync.Mutex
+	count   uint64
+	errors  uint64
+	samples []time.Duration // ring buffer, full once len == cap
+	next    int             // index overwritten by the next sample once full
+}
+
+// newLatencyTracker returns a tracker keeping up to window latencies
+func newLatencyTracker(window int) *latencyTracker {
+	return &latencyTracker{samples: make([]time.Duration, 0, window)}
+}
+
+// record adds the latency and outcome of one call
+func (t *latencyTracker) record(duration time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.count++
+	if err != nil {
+		t.errors++
+	}
+	if len(t.samples) < cap(t.samples) {
+		t.samples = append(t.samples, duration)
+		return
+	}
+	t.samples[t.next] = duration
+	t.next = (t.next + 1) % len(t.samples)
+}
+
+// snapshot returns the counters and the percentiles of the buffered latencies
+func (t *latencyTracker) snapshot() OperationStats {
+	t.mu.Lock()
+	stats := OperationStats{Count: t.count, Errors: t.errors}
+	sorted := slices.Clone(t.samples)
+	t.mu.Unlock()
+
+	// Sort outside the lock, so a snapshot does not stall operations
+	if len(sorted) == 0 {
+		return stats
+	}
+	slices.Sort(sorted)
+	stats.P50 = percentile(sorted, 50)
+	stats.P95 = percentile(sorted, 95)
+	stats.P99 = percentile(sorted, 99)
+	return stats
+}
+
+// percentile returns the p-th percentile of sorted, a non-empty slice, by the
+// nearest-rank method: the smallest sample with at least p% of the samples at
+// or below it
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// serviceStats holds the trackers of a Service created with WithStats
+type serviceStats struct {
+	pseudonymize *latencyTracker
+	revert       *latencyTracker
+}
+
+// Stats returns the counts, error counts and rolling p50, p95 and p99
+// latencies of the pseudonymizations and reverts performed so far. The same
+// calls are counted as reported to the Observer: once per value, including
+// each value of a batch.
+//
+// Collection is off by default, since it adds a lock and a buffer write to
+// every operation; enable it with WithStats. Without it, Stats returns
+// zero values.
+func (s *Service) Stats() Stats {
+	if s.stats == nil {
+		return Stats{}
+	}
+	return Stats{
+		Pseudonymize: s.stats.pseudonymize.snapshot(),
+		Revert:       s.stats.revert.snapshot(),
+	}
+}
@@ -0,0 +1,79 @@
+package pseudonymization
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	svc := NewService(randomKey(t, 32), WithStats(0))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.Pseudonymize("", "test", "test")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+	_, err = svc.PseudonymizeBatch([]string{"a", "b"}, "test", "test")
+	assert.NoError(t, err)
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+
+	stats := svc.Stats()
+	assert.Equal(t, uint64(4), stats.Pseudonymize.Count)
+	assert.Equal(t, uint64(1), stats.Pseudonymize.Errors)
+	assert.Equal(t, uint64(1), stats.Revert.Count)
+	assert.Zero(t, stats.Revert.Errors)
+	assert.Positive(t, stats.Pseudonymize.P99)
+	assert.LessOrEqual(t, stats.Pseudonymize.P50, stats.Pseudonymize.P95)
+	assert.LessOrEqual(t, stats.Pseudonymize.P95, stats.Pseudonymize.P99)
+
+	// Collection is off by default
+	assert.Equal(t, Stats{}, NewService(randomKey(t, 32)).Stats())
+}
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := newLatencyTracker(100)
+	assert.Equal(t, OperationStats{}, tracker.snapshot())
+
+	// Latencies 1..200ms: only the last 100 (101..200ms) are kept
+	for i := 1; i <= 200; i++ {
+		var err error
+		if i%10 == 0 {
+			err = errors.New("failed")
+		}
+		tracker.record(time.Duration(i)*time.Millisecond, err)
+	}
+
+	stats := tracker.snapshot()
+	assert.Equal(t, uint64(200), stats.Count)
+	assert.Equal(t, uint64(20), stats.Errors)
+	assert.Equal(t, 150*time.Millisecond, stats.P50)
+	assert.Equal(t, 195*time.Millisecond, stats.P95)
+	assert.Equal(t, 199*time.Millisecond, stats.P99)
+
+	// A single sample is every percentile
+	tracker = newLatencyTracker(10)
+	tracker.record(time.Second, nil)
+	assert.Equal(t, OperationStats{Count: 1, P50: time.Second, P95: time.Second, P99: time.Second}, tracker.snapshot())
+}
+
+func TestLatencyTrackerConcurrent(t *testing.T) {
+	tracker := newLatencyTracker(16)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				tracker.record(time.Duration(i), nil)
+				tracker.snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(800), tracker.snapshot().Count)
+}
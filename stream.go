@@ -0,0 +1,173 @@
+package pseudonymization
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Stream framing
+//
+// EncryptStream writes a header (stream format version, key version and a
+// random stream ID of streamIDSize bytes) followed by a sequence of chunks.
+// Each chunk is a 4-byte big-endian length, whose top bit marks the final
+// chunk, followed by nonce || ciphertext, under the service's EncryptionMode,
+// of up to streamChunkSize bytes of plaintext. The header, the chunk index,
+// the final flag and the tenant bound with WithTenant are authenticated as
+// additional data, so reordering, dropping or truncating chunks, splicing in
+// chunks of another stream or decrypting as another tenant fails decryption.
+const (
+	streamVersion   = 1
+	streamIDSize    = 16
+	streamChunkSize = 64 * 1024
+	streamFinalFlag = 1 << 31
+)
+
+// EncryptStream encrypts everything read from src and writes it to dst in a
+// chunked, authenticated format, without holding the whole value in memory.
+// Use it for large values such as free-text documents; small values are
+// better served by Pseudonymize, whose output is a single base64 string.
+//
+// Parameters:
+// - dst: Destination of the encrypted stream
+// - src: Plaintext source, read until io.EOF
+//
+// Returns:
+// - error if reading, encrypting or writing fails
+func (s *Service) EncryptStream(dst io.Writer, src io.Reader) error {
+	aead, version, err := s.ring.activeAEAD()
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 2+streamIDSize)
+	header[0], header[1] = streamVersion, byte(version)
+	if _, err := io.ReadFull(s.ring.random, header[2:]); err != nil {
+		return err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+
+	buf := make([]byte, streamChunkSize)
+	var sealed []byte
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(src, buf)
+		final := false
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			final = true
+		default:
+			return err
+		}
+
+		if err := s.ring.useNonce(); err != nil {
+			return err
+		}
+		sealed, err = sealWith(aead, s.ring.random, sealed[:0], buf[:n], s.streamChunkAAD(header, index, final))
+		if err != nil {
+			return err
+		}
+
+		length := uint32(len(sealed))
+		if final {
+			length |= streamFinalFlag
+		}
+		var prefix [4]byte
+		binary.BigEndian.PutUint32(prefix[:], length)
+		if _, err := dst.Write(prefix[:]); err != nil {
+			return err
+		}
+		if _, err := dst.Write(sealed); err != nil {
+			return err
+		}
+
+		if final {
+			return nil
+		}
+	}
+}
+
+// DecryptStream decrypts a stream produced by EncryptStream, writing the
+// plaintext to dst chunk by chunk. Each chunk is authenticated before it is
+// written, but a stream that is truncated or corrupted further on is only
+// detected when DecryptStream reaches that point, so callers must discard
+// whatever was written to dst if an error is returned.
+//
+// Parameters:
+// - dst: Destination of the decrypted plaintext
+// - src: Encrypted stream produced by EncryptStream
+//
+// Returns:
+//   - error wrapping ErrTruncatedStream if the stream ends before its final
+//     chunk, ErrDecryptionFailed if a chunk fails authentication, or
+//     ErrMalformedCiphertext if the framing is invalid
+func (s *Service) DecryptStream(dst io.Writer, src io.Reader) error {
+	var header [2 + streamIDSize]byte
+	if _, err := io.ReadFull(src, header[:]); err != nil {
+		return streamReadError(err)
+	}
+	if header[0] != streamVersion {
+		return fmt.Errorf("%w: unsupported stream version %d", ErrMalformedCiphertext, header[0])
+	}
+	aead, err := s.ring.aeadFor(int(header[1]))
+	if err != nil {
+		return err
+	}
+
+	maxSealed := uint32(aead.NonceSize() + streamChunkSize + aead.Overhead())
+	buf := make([]byte, maxSealed)
+	for index := uint64(0); ; index++ {
+		var prefix [4]byte
+		if _, err := io.ReadFull(src, prefix[:]); err != nil {
+			return streamReadError(err)
+		}
+		length := binary.BigEndian.Uint32(prefix[:])
+		final := length&streamFinalFlag != 0
+		length &^= streamFinalFlag
+		if length > maxSealed {
+			return fmt.Errorf("%w: chunk of %d bytes exceeds maximum", ErrMalformedCiphertext, length)
+		}
+
+		sealed := buf[:length]
+		if _, err := io.ReadFull(src, sealed); err != nil {
+			return streamReadError(err)
+		}
+
+		plaintext, err := openWith(aead, sealed, s.streamChunkAAD(header[:], index, final))
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", index, err)
+		}
+		if _, err := dst.Write(plaintext); err != nil {
+			return err
+		}
+
+		if final {
+			// Nothing may follow the final chunk
+			if n, _ := src.Read(prefix[:1]); n > 0 {
+				return fmt.Errorf("%w: trailing data after final chunk", ErrMalformedCiphertext)
+			}
+			return nil
+		}
+	}
+}
+
+// streamChunkAAD encodes the stream header and the position of a chunk as
+// additional authenticated data, bound to the service tenant
+func (s *Service) streamChunkAAD(header []byte, index uint64, final bool) []byte {
+	aad := make([]byte, len(header)+9)
+	copy(aad, header)
+	binary.BigEndian.PutUint64(aad[len(header):], index)
+	if final {
+		aad[len(aad)-1] = 1
+	}
+	return s.tenantAAD(aad)
+}
+
+// streamReadError maps an unexpected end of input to ErrTruncatedStream
+func streamReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncatedStream
+	}
+	return err
+}
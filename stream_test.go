@@ -0,0 +1,88 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamRoundTrip(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	sizes := []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 17}
+	for _, size := range sizes {
+		plaintext := make([]byte, size)
+		_, err := rand.Read(plaintext)
+		assert.NoError(t, err)
+
+		var encrypted bytes.Buffer
+		assert.NoError(t, svc.EncryptStream(&encrypted, bytes.NewReader(plaintext)))
+
+		var decrypted bytes.Buffer
+		assert.NoError(t, svc.DecryptStream(&decrypted, bytes.NewReader(encrypted.Bytes())))
+		assert.True(t, bytes.Equal(plaintext, decrypted.Bytes()), "size %d", size)
+	}
+}
+
+func TestStreamTampering(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	plaintext := make([]byte, 2*streamChunkSize+100)
+	_, err := rand.Read(plaintext)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, svc.EncryptStream(&buf, bytes.NewReader(plaintext)))
+	encrypted := buf.Bytes()
+	const h = 2 + streamIDSize
+	chunkLen := 4 + 12 + streamChunkSize + 16
+
+	// Truncated in the middle of a chunk
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(encrypted[:len(encrypted)-10]))
+	assert.ErrorIs(t, err, ErrTruncatedStream)
+
+	// Truncated at a chunk boundary, dropping the final chunk
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(encrypted[:h+2*chunkLen]))
+	assert.ErrorIs(t, err, ErrTruncatedStream)
+
+	// Truncated inside the header
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(encrypted[:h-1]))
+	assert.ErrorIs(t, err, ErrTruncatedStream)
+
+	// Final flag forged on an earlier chunk
+	forged := append([]byte(nil), encrypted[:h+chunkLen]...)
+	forged[h] |= 0x80
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(forged))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Chunks reordered
+	reordered := append([]byte(nil), encrypted[:h]...)
+	reordered = append(reordered, encrypted[h+chunkLen:h+2*chunkLen]...)
+	reordered = append(reordered, encrypted[h:h+chunkLen]...)
+	reordered = append(reordered, encrypted[h+2*chunkLen:]...)
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(reordered))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Chunks spliced from another stream under the same key
+	var other bytes.Buffer
+	assert.NoError(t, svc.EncryptStream(&other, bytes.NewReader(plaintext)))
+	spliced := append([]byte(nil), encrypted[:h+chunkLen]...)
+	spliced = append(spliced, other.Bytes()[h+chunkLen:]...)
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(spliced))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Trailing data after the final chunk
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(append(append([]byte(nil), encrypted...), 0)))
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+
+	// Unknown format version
+	relabeled := append([]byte{streamVersion + 1}, encrypted[1:]...)
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(relabeled))
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+
+	// Wrong key
+	err = NewService(randomKey(t, 32)).DecryptStream(&bytes.Buffer{}, bytes.NewReader(encrypted))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
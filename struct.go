@@ -0,0 +1,119 @@
+package pseudonymization
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// StructTag is the struct tag that marks a field for PseudonymizeStruct
+const StructTag = "pseudonymize"
+
+// structField is a tagged field found by PseudonymizeStruct
+type structField struct {
+	path  string
+	value reflect.Value // the string to replace
+}
+
+// PseudonymizeStruct replaces every string field of the struct v points to
+// that is tagged `pseudonymize:"true"` with its pseudonym. Exported fields of
+// nested structs and of non-nil struct pointers are searched as well; tagged
+// fields may be string or *string, and empty (or nil) ones are skipped.
+//
+// Either every tagged field is replaced or, on error, none is. The returned
+// Results hold the hash and ciphertext of each original value, for Revert;
+// store them before discarding the original data.
+//
+// Example:
+//
+//	type Customer struct {
+//		Name    string `pseudonymize:"true"`
+//		Address struct {
+//			Street string `pseudonymize:"true"`
+//		}
+//	}
+//	results, err := svc.PseudonymizeStruct(&customer, "analytics", "crm")
+//	// results["Name"], results["Address.Street"]
+//
+// Parameters:
+// - v: A non-nil pointer to the struct to pseudonymize in place
+// - purpose: Reason for pseudonymization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+//   - Results by field path, with nested fields joined by dots
+//   - error wrapping ErrInvalidStruct if v is not a non-nil struct pointer or
+//     a tagged field is not a string, or the error of the first field that
+//     failed to pseudonymize
+func (s *Service) PseudonymizeStruct(v interface{}, purpose, system string) (map[string]*Result, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: got %T", ErrInvalidStruct, v)
+	}
+
+	var fields []structField
+	visited := map[uintptr]bool{rv.Pointer(): true}
+	if err := collectStructFields(rv.Elem(), "", visited, &fields); err != nil {
+		return nil, err
+	}
+
+	// Pseudonymize everything before touching the struct, so a failure
+	// leaves it unchanged
+	results := make(map[string]*Result, len(fields))
+	for _, field := range fields {
+		result, err := s.Pseudonymize(field.value.String(), purpose, system)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.path, err)
+		}
+		results[field.path] = result
+	}
+
+	for _, field := range fields {
+		field.value.SetString(results[field.path].Pseudonym)
+	}
+	return results, nil
+}
+
+// collectStructFields appends the non-empty tagged strings of the struct rv
+// to fields, recursing into nested structs and struct pointers. visited holds
+// the struct pointers already searched, so cyclic data terminates.
+func collectStructFields(rv reflect.Value, prefix string, visited map[uintptr]bool, fields *[]structField) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		path := prefix + sf.Name
+		fv := rv.Field(i)
+
+		if sf.Tag.Get(StructTag) == "true" {
+			if fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.String {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() != reflect.String {
+				return fmt.Errorf("%w: field %s is %s, not a string", ErrInvalidStruct, path, sf.Type)
+			}
+			if fv.Len() > 0 {
+				*fields = append(*fields, structField{path: path, value: fv})
+			}
+			continue
+		}
+
+		if fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() || visited[fv.Pointer()] {
+				continue
+			}
+			visited[fv.Pointer()] = true
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			if err := collectStructFields(fv, path+".", visited, fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
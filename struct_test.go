@@ -0,0 +1,96 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAddress struct {
+	Street string `pseudonymize:"true"`
+	City   string
+}
+
+type testCustomer struct {
+	Name     string  `pseudonymize:"true"`
+	Nickname *string `pseudonymize:"true"`
+	Email    string  `pseudonymize:"true"`
+	Country  string
+	Address  testAddress
+	Billing  *testAddress
+	Referrer *testCustomer
+	secret   string `pseudonymize:"true"`
+}
+
+func TestPseudonymizeStruct(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	nickname := "Zé"
+	customer := &testCustomer{
+		Name:     "José da Silva",
+		Nickname: &nickname,
+		Country:  "BR",
+		Address:  testAddress{Street: "Rua A, 1", City: "São Paulo"},
+		Billing:  &testAddress{Street: "Rua B, 2", City: "Campinas"},
+		secret:   "unexported",
+	}
+	customer.Referrer = customer // cycles terminate
+
+	results, err := svc.PseudonymizeStruct(customer, "analytics", "crm")
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+
+	originals := map[string]string{
+		"Name":           "José da Silva",
+		"Nickname":       "Zé",
+		"Address.Street": "Rua A, 1",
+		"Billing.Street": "Rua B, 2",
+	}
+	replaced := map[string]string{
+		"Name":           customer.Name,
+		"Nickname":       nickname,
+		"Address.Street": customer.Address.Street,
+		"Billing.Street": customer.Billing.Street,
+	}
+	for path, original := range originals {
+		result := results[path]
+		if assert.NotNil(t, result, path) {
+			assert.Equal(t, result.Pseudonym, replaced[path], path)
+			value, err := svc.Revert(result.EncryptedValue)
+			assert.NoError(t, err)
+			assert.Equal(t, original, value, path)
+		}
+	}
+
+	// Untagged, empty and unexported fields are left alone
+	assert.Equal(t, "BR", customer.Country)
+	assert.Equal(t, "São Paulo", customer.Address.City)
+	assert.Empty(t, customer.Email)
+	assert.Equal(t, "unexported", customer.secret)
+}
+
+func TestPseudonymizeStructInvalid(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	for _, v := range []interface{}{nil, testCustomer{}, (*testCustomer)(nil), new(string)} {
+		_, err := svc.PseudonymizeStruct(v, "test", "test")
+		assert.ErrorIs(t, err, ErrInvalidStruct)
+	}
+
+	// A tagged non-string field fails without modifying the struct
+	record := &struct {
+		Name string `pseudonymize:"true"`
+		Age  int    `pseudonymize:"true"`
+	}{Name: "José", Age: 42}
+	_, err := svc.PseudonymizeStruct(record, "test", "test")
+	assert.ErrorIs(t, err, ErrInvalidStruct)
+	assert.Contains(t, err.Error(), "field Age")
+	assert.Equal(t, "José", record.Name)
+
+	// So does a field that fails to pseudonymize
+	svc.Close()
+	customer := &testCustomer{Name: "José"}
+	_, err = svc.PseudonymizeStruct(customer, "test", "test")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+	assert.Equal(t, "José", customer.Name)
+}
@@ -0,0 +1,81 @@
+package pseudonymization
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTenant(t *testing.T) {
+	key := randomKey(t, 32)
+	svc := NewService(key)
+	tenantA := svc.WithTenant("tenant-a")
+	tenantB := svc.WithTenant("tenant-b")
+	assert.Equal(t, "tenant-a", tenantA.Tenant())
+	assert.Empty(t, svc.Tenant())
+
+	// The derived services share the keyring of their parent
+	assert.Same(t, svc.ring, tenantA.ring)
+
+	result, err := tenantA.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	original, err := tenantA.Revert(result.EncryptedValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// A ciphertext from tenant A does not decrypt under tenant B, nor without a tenant
+	_, err = tenantB.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = svc.Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = tenantA.WithTenant("").Revert(result.EncryptedValue)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// And the other way around
+	untenanted, err := svc.Encrypt("52998224725")
+	assert.NoError(t, err)
+	_, err = tenantA.Decrypt(untenanted)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Tenant binding composes with caller-supplied additional data
+	encrypted, err := tenantA.EncryptWithAAD("52998224725", []byte("record-1"))
+	assert.NoError(t, err)
+	original, err = tenantA.DecryptWithAAD(encrypted, []byte("record-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+	_, err = tenantB.DecryptWithAAD(encrypted, []byte("record-1"))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// RevertAny and ReEncrypt honor the tenant
+	_, keyIndex, err := tenantA.RevertAny(result.EncryptedValue, [][]byte{key})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, keyIndex)
+	_, _, err = tenantB.RevertAny(result.EncryptedValue, [][]byte{key})
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	newKey := randomKey(t, 32)
+	reencrypted, err := NewService(newKey).WithTenant("tenant-a").ReEncrypt(result.EncryptedValue, key)
+	assert.NoError(t, err)
+	_, err = NewService(newKey).Revert(reencrypted)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	original, err = NewService(newKey).WithTenant("tenant-a").Revert(reencrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// So do streams
+	var stream bytes.Buffer
+	assert.NoError(t, tenantA.EncryptStream(&stream, bytes.NewReader([]byte("52998224725"))))
+	var decrypted bytes.Buffer
+	assert.NoError(t, tenantA.DecryptStream(&decrypted, bytes.NewReader(stream.Bytes())))
+	assert.Equal(t, "52998224725", decrypted.String())
+	err = tenantB.DecryptStream(&bytes.Buffer{}, bytes.NewReader(stream.Bytes()))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	err = svc.DecryptStream(&bytes.Buffer{}, bytes.NewReader(stream.Bytes()))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Closing the parent closes every derived service
+	svc.Close()
+	_, err = tenantA.Encrypt("52998224725")
+	assert.ErrorIs(t, err, ErrServiceClosed)
+}
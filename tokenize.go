@@ -0,0 +1,150 @@
+package pseudonymization
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// tokenSize is the number of random bytes in a token
+const tokenSize = 16
+
+// TokenVault persists the encrypted value behind each token issued by
+// Service.Tokenize. Implementations must be safe for concurrent use and
+// should return an error wrapping ErrTokenNotFound from Load for unknown
+// tokens. The vault only ever sees ciphertexts, never original values.
+type TokenVault interface {
+	Store(token, encrypted string) error
+	Load(token string) (string, error)
+}
+
+// MemoryTokenVault is an in-memory TokenVault intended for tests and
+// prototypes; its contents are lost when the process exits
+type MemoryTokenVault struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewMemoryTokenVault creates an empty MemoryTokenVault
+func NewMemoryTokenVault() *MemoryTokenVault {
+	return &MemoryTokenVault{values: make(map[string]string)}
+}
+
+// Store saves encrypted under token, replacing any previous value
+func (v *MemoryTokenVault) Store(token, encrypted string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[token] = encrypted
+	return nil
+}
+
+// Load returns the encrypted value stored under token
+func (v *MemoryTokenVault) Load(token string) (string, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	encrypted, ok := v.values[token]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return encrypted, nil
+}
+
+// Tokenize replaces value with a short opaque token. The value is encrypted
+// and persisted in the vault configured with WithTokenVault, so records only
+// carry the token. The ciphertext is bound to its token, so swapping entries
+// in the vault makes Detokenize fail instead of returning another value.
+//
+// Parameters:
+// - value: The sensitive value to tokenize
+// - purpose: Reason for tokenization (for audit trails)
+// - system: Originating system (for audit trails)
+//
+// Returns:
+// - URL-safe token of 22 characters
+// - error if no vault is configured, or encryption or storage fails
+func (s *Service) Tokenize(value, purpose, system string) (_ string, err error) {
+	defer s.observePseudonymize(time.Now(), &err)
+
+	if s.tokenVault == nil {
+		return "", ErrNoTokenVault
+	}
+	if err = s.checkLength(len(value)); err != nil {
+		return "", err
+	}
+	if value, err = s.normalizeInput(value); err != nil {
+		return "", err
+	}
+	if len(value) == 0 {
+		return "", ErrEmptyValue
+	}
+	if err := s.checkNotPseudonym(value); err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, tokenSize)
+	if _, err := io.ReadFull(s.ring.random, raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	hash, err := s.originalHash(value)
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := s.EncryptWithAAD(value, []byte(token))
+	if err != nil {
+		return "", fmt.Errorf("encryption failed: %w", err)
+	}
+	if err := s.tokenVault.Store(token, encrypted); err != nil {
+		return "", fmt.Errorf("storing token: %w", err)
+	}
+
+	s.audit(context.Background(), AuditEvent{
+		Operation:    OperationTokenize,
+		OriginalHash: hash,
+		Pseudonym:    token,
+		Purpose:      purpose,
+		System:       system,
+	})
+	return token, nil
+}
+
+// Detokenize loads the encrypted value behind token from the vault and
+// decrypts it
+//
+// Parameters:
+// - token: A token returned by Tokenize
+//
+// Returns:
+// - Original plaintext value
+// - error if no vault is configured, the token is unknown or decryption fails
+func (s *Service) Detokenize(token string) (_ string, err error) {
+	defer s.observeRevert(time.Now(), &err)
+
+	if s.tokenVault == nil {
+		return "", ErrNoTokenVault
+	}
+	if err := s.allowRevert(context.Background()); err != nil {
+		return "", err
+	}
+
+	encrypted, err := s.tokenVault.Load(token)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := s.DecryptWithAAD(encrypted, []byte(token))
+	if err != nil {
+		return "", err
+	}
+
+	hash, _ := s.originalHash(plaintext)
+	s.audit(context.Background(), AuditEvent{
+		Operation:    OperationDetokenize,
+		OriginalHash: hash,
+		Pseudonym:    token,
+	})
+	return plaintext, nil
+}
@@ -0,0 +1,57 @@
+package pseudonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	vault := NewMemoryTokenVault()
+	logger := &recordingAuditLogger{}
+	svc := NewService(randomKey(t, 32), WithTokenVault(vault), WithAuditLogger(logger))
+
+	token, err := svc.Tokenize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	assert.Len(t, token, 22)
+
+	other, err := svc.Tokenize("52998224725", "billing", "erp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	original, err := svc.Detokenize(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", original)
+
+	// The vault holds ciphertext only
+	encrypted, err := vault.Load(token)
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "52998224725")
+
+	// Ciphertexts are bound to their token
+	otherEncrypted, err := vault.Load(other)
+	assert.NoError(t, err)
+	assert.NoError(t, vault.Store(token, otherEncrypted))
+	_, err = svc.Detokenize(token)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = svc.Detokenize("unknown")
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+	_, err = svc.Tokenize("", "billing", "erp")
+	assert.ErrorIs(t, err, ErrEmptyValue)
+
+	assert.Len(t, logger.events, 3)
+	assert.Equal(t, OperationTokenize, logger.events[0].Operation)
+	assert.Equal(t, token, logger.events[0].Pseudonym)
+	assert.Equal(t, "billing", logger.events[0].Purpose)
+	assert.Equal(t, OperationDetokenize, logger.events[2].Operation)
+}
+
+func TestTokenizeWithoutVault(t *testing.T) {
+	svc := NewService(randomKey(t, 32))
+
+	_, err := svc.Tokenize("52998224725", "billing", "erp")
+	assert.ErrorIs(t, err, ErrNoTokenVault)
+	_, err = svc.Detokenize("token")
+	assert.ErrorIs(t, err, ErrNoTokenVault)
+}
@@ -0,0 +1,5 @@
+// Package pseudonymizationpb contains the protocol buffer and gRPC bindings
+// generated from pseudonymization.proto
+package pseudonymizationpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pseudonymization.proto
@@ -0,0 +1,430 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: pseudonymization.proto
+
+package pseudonymizationpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PseudonymizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Purpose       string                 `protobuf:"bytes,2,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	System        string                 `protobuf:"bytes,3,opt,name=system,proto3" json:"system,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PseudonymizeRequest) Reset() {
+	*x = PseudonymizeRequest{}
+	mi := &file_pseudonymization_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PseudonymizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PseudonymizeRequest) ProtoMessage() {}
+
+func (x *PseudonymizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pseudonymization_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PseudonymizeRequest.ProtoReflect.Descriptor instead.
+func (*PseudonymizeRequest) Descriptor() ([]byte, []int) {
+	return file_pseudonymization_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PseudonymizeRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *PseudonymizeRequest) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *PseudonymizeRequest) GetSystem() string {
+	if x != nil {
+		return x.System
+	}
+	return ""
+}
+
+type PseudonymizeResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OriginalHash   string                 `protobuf:"bytes,1,opt,name=original_hash,json=originalHash,proto3" json:"original_hash,omitempty"`
+	Pseudonym      string                 `protobuf:"bytes,2,opt,name=pseudonym,proto3" json:"pseudonym,omitempty"`
+	EncryptedValue string                 `protobuf:"bytes,3,opt,name=encrypted_value,json=encryptedValue,proto3" json:"encrypted_value,omitempty"`
+	Timestamp      int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PseudonymizeResponse) Reset() {
+	*x = PseudonymizeResponse{}
+	mi := &file_pseudonymization_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PseudonymizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PseudonymizeResponse) ProtoMessage() {}
+
+func (x *PseudonymizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pseudonymization_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PseudonymizeResponse.ProtoReflect.Descriptor instead.
+func (*PseudonymizeResponse) Descriptor() ([]byte, []int) {
+	return file_pseudonymization_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PseudonymizeResponse) GetOriginalHash() string {
+	if x != nil {
+		return x.OriginalHash
+	}
+	return ""
+}
+
+func (x *PseudonymizeResponse) GetPseudonym() string {
+	if x != nil {
+		return x.Pseudonym
+	}
+	return ""
+}
+
+func (x *PseudonymizeResponse) GetEncryptedValue() string {
+	if x != nil {
+		return x.EncryptedValue
+	}
+	return ""
+}
+
+func (x *PseudonymizeResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type RevertRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	EncryptedValue string                 `protobuf:"bytes,1,opt,name=encrypted_value,json=encryptedValue,proto3" json:"encrypted_value,omitempty"`
+	Purpose        string                 `protobuf:"bytes,2,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	System         string                 `protobuf:"bytes,3,opt,name=system,proto3" json:"system,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RevertRequest) Reset() {
+	*x = RevertRequest{}
+	mi := &file_pseudonymization_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevertRequest) ProtoMessage() {}
+
+func (x *RevertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pseudonymization_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevertRequest.ProtoReflect.Descriptor instead.
+func (*RevertRequest) Descriptor() ([]byte, []int) {
+	return file_pseudonymization_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RevertRequest) GetEncryptedValue() string {
+	if x != nil {
+		return x.EncryptedValue
+	}
+	return ""
+}
+
+func (x *RevertRequest) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *RevertRequest) GetSystem() string {
+	if x != nil {
+		return x.System
+	}
+	return ""
+}
+
+type RevertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevertResponse) Reset() {
+	*x = RevertResponse{}
+	mi := &file_pseudonymization_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevertResponse) ProtoMessage() {}
+
+func (x *RevertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pseudonymization_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevertResponse.ProtoReflect.Descriptor instead.
+func (*RevertResponse) Descriptor() ([]byte, []int) {
+	return file_pseudonymization_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RevertResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type HashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HashRequest) Reset() {
+	*x = HashRequest{}
+	mi := &file_pseudonymization_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashRequest) ProtoMessage() {}
+
+func (x *HashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pseudonymization_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashRequest.ProtoReflect.Descriptor instead.
+func (*HashRequest) Descriptor() ([]byte, []int) {
+	return file_pseudonymization_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HashRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type HashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HashResponse) Reset() {
+	*x = HashResponse{}
+	mi := &file_pseudonymization_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashResponse) ProtoMessage() {}
+
+func (x *HashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pseudonymization_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashResponse.ProtoReflect.Descriptor instead.
+func (*HashResponse) Descriptor() ([]byte, []int) {
+	return file_pseudonymization_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HashResponse) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+var File_pseudonymization_proto protoreflect.FileDescriptor
+
+const file_pseudonymization_proto_rawDesc = "" +
+	"\n" +
+	"\x16pseudonymization.proto\x12\x13pseudonymization.v1\"]\n" +
+	"\x13PseudonymizeRequest\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x18\n" +
+	"\apurpose\x18\x02 \x01(\tR\apurpose\x12\x16\n" +
+	"\x06system\x18\x03 \x01(\tR\x06system\"\xa0\x01\n" +
+	"\x14PseudonymizeResponse\x12#\n" +
+	"\roriginal_hash\x18\x01 \x01(\tR\foriginalHash\x12\x1c\n" +
+	"\tpseudonym\x18\x02 \x01(\tR\tpseudonym\x12'\n" +
+	"\x0fencrypted_value\x18\x03 \x01(\tR\x0eencryptedValue\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"j\n" +
+	"\rRevertRequest\x12'\n" +
+	"\x0fencrypted_value\x18\x01 \x01(\tR\x0eencryptedValue\x12\x18\n" +
+	"\apurpose\x18\x02 \x01(\tR\apurpose\x12\x16\n" +
+	"\x06system\x18\x03 \x01(\tR\x06system\"&\n" +
+	"\x0eRevertResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\"#\n" +
+	"\vHashRequest\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\"\"\n" +
+	"\fHashResponse\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash2\x97\x02\n" +
+	"\x10Pseudonymization\x12c\n" +
+	"\fPseudonymize\x12(.pseudonymization.v1.PseudonymizeRequest\x1a).pseudonymization.v1.PseudonymizeResponse\x12Q\n" +
+	"\x06Revert\x12\".pseudonymization.v1.RevertRequest\x1a#.pseudonymization.v1.RevertResponse\x12K\n" +
+	"\x04Hash\x12 .pseudonymization.v1.HashRequest\x1a!.pseudonymization.v1.HashResponseBRZPgithub.com/raywall/pseudonymization-lgpd-tools/transport/grpc/pseudonymizationpbb\x06proto3"
+
+var (
+	file_pseudonymization_proto_rawDescOnce sync.Once
+	file_pseudonymization_proto_rawDescData []byte
+)
+
+func file_pseudonymization_proto_rawDescGZIP() []byte {
+	file_pseudonymization_proto_rawDescOnce.Do(func() {
+		file_pseudonymization_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pseudonymization_proto_rawDesc), len(file_pseudonymization_proto_rawDesc)))
+	})
+	return file_pseudonymization_proto_rawDescData
+}
+
+var file_pseudonymization_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_pseudonymization_proto_goTypes = []any{
+	(*PseudonymizeRequest)(nil),  // 0: pseudonymization.v1.PseudonymizeRequest
+	(*PseudonymizeResponse)(nil), // 1: pseudonymization.v1.PseudonymizeResponse
+	(*RevertRequest)(nil),        // 2: pseudonymization.v1.RevertRequest
+	(*RevertResponse)(nil),       // 3: pseudonymization.v1.RevertResponse
+	(*HashRequest)(nil),          // 4: pseudonymization.v1.HashRequest
+	(*HashResponse)(nil),         // 5: pseudonymization.v1.HashResponse
+}
+var file_pseudonymization_proto_depIdxs = []int32{
+	0, // 0: pseudonymization.v1.Pseudonymization.Pseudonymize:input_type -> pseudonymization.v1.PseudonymizeRequest
+	2, // 1: pseudonymization.v1.Pseudonymization.Revert:input_type -> pseudonymization.v1.RevertRequest
+	4, // 2: pseudonymization.v1.Pseudonymization.Hash:input_type -> pseudonymization.v1.HashRequest
+	1, // 3: pseudonymization.v1.Pseudonymization.Pseudonymize:output_type -> pseudonymization.v1.PseudonymizeResponse
+	3, // 4: pseudonymization.v1.Pseudonymization.Revert:output_type -> pseudonymization.v1.RevertResponse
+	5, // 5: pseudonymization.v1.Pseudonymization.Hash:output_type -> pseudonymization.v1.HashResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_pseudonymization_proto_init() }
+func file_pseudonymization_proto_init() {
+	if File_pseudonymization_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pseudonymization_proto_rawDesc), len(file_pseudonymization_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pseudonymization_proto_goTypes,
+		DependencyIndexes: file_pseudonymization_proto_depIdxs,
+		MessageInfos:      file_pseudonymization_proto_msgTypes,
+	}.Build()
+	File_pseudonymization_proto = out.File
+	file_pseudonymization_proto_goTypes = nil
+	file_pseudonymization_proto_depIdxs = nil
+}
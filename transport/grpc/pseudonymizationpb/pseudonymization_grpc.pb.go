@@ -0,0 +1,197 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: pseudonymization.proto
+
+package pseudonymizationpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Pseudonymization_Pseudonymize_FullMethodName = "/pseudonymization.v1.Pseudonymization/Pseudonymize"
+	Pseudonymization_Revert_FullMethodName       = "/pseudonymization.v1.Pseudonymization/Revert"
+	Pseudonymization_Hash_FullMethodName         = "/pseudonymization.v1.Pseudonymization/Hash"
+)
+
+// PseudonymizationClient is the client API for Pseudonymization service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PseudonymizationClient interface {
+	Pseudonymize(ctx context.Context, in *PseudonymizeRequest, opts ...grpc.CallOption) (*PseudonymizeResponse, error)
+	Revert(ctx context.Context, in *RevertRequest, opts ...grpc.CallOption) (*RevertResponse, error)
+	Hash(ctx context.Context, in *HashRequest, opts ...grpc.CallOption) (*HashResponse, error)
+}
+
+type pseudonymizationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPseudonymizationClient(cc grpc.ClientConnInterface) PseudonymizationClient {
+	return &pseudonymizationClient{cc}
+}
+
+func (c *pseudonymizationClient) Pseudonymize(ctx context.Context, in *PseudonymizeRequest, opts ...grpc.CallOption) (*PseudonymizeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PseudonymizeResponse)
+	err := c.cc.Invoke(ctx, Pseudonymization_Pseudonymize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pseudonymizationClient) Revert(ctx context.Context, in *RevertRequest, opts ...grpc.CallOption) (*RevertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevertResponse)
+	err := c.cc.Invoke(ctx, Pseudonymization_Revert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pseudonymizationClient) Hash(ctx context.Context, in *HashRequest, opts ...grpc.CallOption) (*HashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HashResponse)
+	err := c.cc.Invoke(ctx, Pseudonymization_Hash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PseudonymizationServer is the server API for Pseudonymization service.
+// All implementations must embed UnimplementedPseudonymizationServer
+// for forward compatibility.
+type PseudonymizationServer interface {
+	Pseudonymize(context.Context, *PseudonymizeRequest) (*PseudonymizeResponse, error)
+	Revert(context.Context, *RevertRequest) (*RevertResponse, error)
+	Hash(context.Context, *HashRequest) (*HashResponse, error)
+	mustEmbedUnimplementedPseudonymizationServer()
+}
+
+// UnimplementedPseudonymizationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPseudonymizationServer struct{}
+
+func (UnimplementedPseudonymizationServer) Pseudonymize(context.Context, *PseudonymizeRequest) (*PseudonymizeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Pseudonymize not implemented")
+}
+func (UnimplementedPseudonymizationServer) Revert(context.Context, *RevertRequest) (*RevertResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Revert not implemented")
+}
+func (UnimplementedPseudonymizationServer) Hash(context.Context, *HashRequest) (*HashResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Hash not implemented")
+}
+func (UnimplementedPseudonymizationServer) mustEmbedUnimplementedPseudonymizationServer() {}
+func (UnimplementedPseudonymizationServer) testEmbeddedByValue()                          {}
+
+// UnsafePseudonymizationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PseudonymizationServer will
+// result in compilation errors.
+type UnsafePseudonymizationServer interface {
+	mustEmbedUnimplementedPseudonymizationServer()
+}
+
+func RegisterPseudonymizationServer(s grpc.ServiceRegistrar, srv PseudonymizationServer) {
+	// If the following call panics, it indicates UnimplementedPseudonymizationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Pseudonymization_ServiceDesc, srv)
+}
+
+func _Pseudonymization_Pseudonymize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PseudonymizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PseudonymizationServer).Pseudonymize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Pseudonymization_Pseudonymize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PseudonymizationServer).Pseudonymize(ctx, req.(*PseudonymizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pseudonymization_Revert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PseudonymizationServer).Revert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Pseudonymization_Revert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PseudonymizationServer).Revert(ctx, req.(*RevertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pseudonymization_Hash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PseudonymizationServer).Hash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Pseudonymization_Hash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PseudonymizationServer).Hash(ctx, req.(*HashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Pseudonymization_ServiceDesc is the grpc.ServiceDesc for Pseudonymization service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Pseudonymization_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pseudonymization.v1.Pseudonymization",
+	HandlerType: (*PseudonymizationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Pseudonymize",
+			Handler:    _Pseudonymization_Pseudonymize_Handler,
+		},
+		{
+			MethodName: "Revert",
+			Handler:    _Pseudonymization_Revert_Handler,
+		},
+		{
+			MethodName: "Hash",
+			Handler:    _Pseudonymization_Hash_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pseudonymization.proto",
+}
@@ -0,0 +1,105 @@
+// Package grpc exposes a pseudonymization.Service as the gRPC service
+// defined in pseudonymizationpb/pseudonymization.proto, so it can be called
+// from any language with gRPC support.
+//
+// Sentinel errors are mapped to status codes: invalid input (empty values,
+// values that are already pseudonyms, malformed or unauthenticated
+// ciphertexts) yields InvalidArgument, and a closed service or one whose key
+// reached its nonce limit yields FailedPrecondition. Like transport/http, the
+// server does not authenticate callers; use gRPC credentials or interceptors.
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/raywall/pseudonymization-lgpd-tools"
+	"github.com/raywall/pseudonymization-lgpd-tools/transport/grpc/pseudonymizationpb"
+)
+
+// Server implements pseudonymizationpb.PseudonymizationServer on top of a
+// pseudonymization.Service
+type Server struct {
+	pseudonymizationpb.UnimplementedPseudonymizationServer
+
+	svc *pseudonymization.Service
+}
+
+// NewServer creates a gRPC server implementation backed by svc. Register it
+// with pseudonymizationpb.RegisterPseudonymizationServer.
+//
+// Parameters:
+// - svc: The service performing the operations
+//
+// Returns:
+// - Server ready to be registered
+func NewServer(svc *pseudonymization.Service) *Server {
+	return &Server{svc: svc}
+}
+
+// Pseudonymize hashes and encrypts a value and assigns it a pseudonym
+func (s *Server) Pseudonymize(ctx context.Context, req *pseudonymizationpb.PseudonymizeRequest) (*pseudonymizationpb.PseudonymizeResponse, error) {
+	result, err := s.svc.PseudonymizeContext(ctx, req.GetValue(), req.GetPurpose(), req.GetSystem())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &pseudonymizationpb.PseudonymizeResponse{
+		OriginalHash:   result.OriginalHash,
+		Pseudonym:      result.Pseudonym,
+		EncryptedValue: result.EncryptedValue,
+		Timestamp:      result.Timestamp,
+	}, nil
+}
+
+// Revert decrypts a value produced by Pseudonymize
+func (s *Server) Revert(ctx context.Context, req *pseudonymizationpb.RevertRequest) (*pseudonymizationpb.RevertResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	value, err := s.svc.RevertWithContext(req.GetEncryptedValue(), req.GetPurpose(), req.GetSystem())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pseudonymizationpb.RevertResponse{Value: value}, nil
+}
+
+// Hash returns the hash of a value, keyed when the service has an HMAC key
+func (s *Server) Hash(_ context.Context, req *pseudonymizationpb.HashRequest) (*pseudonymizationpb.HashResponse, error) {
+	if req.GetValue() == "" {
+		return nil, statusError(pseudonymization.ErrEmptyValue)
+	}
+
+	hash := s.svc.HashKeyed(req.GetValue())
+	if hash == "" {
+		return nil, statusError(pseudonymization.ErrServiceClosed)
+	}
+	return &pseudonymizationpb.HashResponse{Hash: hash}, nil
+}
+
+// statusError maps err to a gRPC status error
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, pseudonymization.ErrEmptyValue),
+		errors.Is(err, pseudonymization.ErrValueTooLarge),
+		errors.Is(err, pseudonymization.ErrAlreadyPseudonymized),
+		errors.Is(err, pseudonymization.ErrMalformedCiphertext),
+		errors.Is(err, pseudonymization.ErrCiphertextTooShort),
+		errors.Is(err, pseudonymization.ErrNotReversible),
+		errors.Is(err, pseudonymization.ErrDecryptionFailed):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, pseudonymization.ErrRateLimited):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, pseudonymization.ErrServiceClosed),
+		errors.Is(err, pseudonymization.ErrNonceLimitApproaching):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
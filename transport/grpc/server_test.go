@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/raywall/pseudonymization-lgpd-tools"
+	"github.com/raywall/pseudonymization-lgpd-tools/transport/grpc/pseudonymizationpb"
+)
+
+func newTestClient(t *testing.T, opts ...pseudonymization.Option) (pseudonymizationpb.PseudonymizationClient, *pseudonymization.Service) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	svc := pseudonymization.NewService(key, opts...)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpcgo.NewServer()
+	pseudonymizationpb.RegisterPseudonymizationServer(server, NewServer(svc))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpcgo.NewClient("passthrough:///bufnet",
+		grpcgo.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpcgo.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pseudonymizationpb.NewPseudonymizationClient(conn), svc
+}
+
+func TestServer(t *testing.T) {
+	client, svc := newTestClient(t)
+	ctx := context.Background()
+
+	resp, err := client.Pseudonymize(ctx, &pseudonymizationpb.PseudonymizeRequest{
+		Value:   "52998224725",
+		Purpose: "billing",
+		System:  "erp",
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.GetPseudonym())
+	assert.Equal(t, svc.HashKeyed("52998224725"), resp.GetOriginalHash())
+
+	reverted, err := client.Revert(ctx, &pseudonymizationpb.RevertRequest{EncryptedValue: resp.GetEncryptedValue()})
+	assert.NoError(t, err)
+	assert.Equal(t, "52998224725", reverted.GetValue())
+
+	hash, err := client.Hash(ctx, &pseudonymizationpb.HashRequest{Value: "52998224725"})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.GetOriginalHash(), hash.GetHash())
+}
+
+func TestServerErrors(t *testing.T) {
+	client, svc := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.Pseudonymize(ctx, &pseudonymizationpb.PseudonymizeRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Hash(ctx, &pseudonymizationpb.HashRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Pseudonymize(ctx, &pseudonymizationpb.PseudonymizeRequest{Value: strings.Repeat("x", pseudonymization.DefaultMaxValueLength+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Revert(ctx, &pseudonymizationpb.RevertRequest{EncryptedValue: "not base64!"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Revert(ctx, &pseudonymizationpb.RevertRequest{EncryptedValue: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, svc.Close())
+	_, err = client.Pseudonymize(ctx, &pseudonymizationpb.PseudonymizeRequest{Value: "52998224725"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = client.Hash(ctx, &pseudonymizationpb.HashRequest{Value: "52998224725"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestServerAlreadyPseudonymized(t *testing.T) {
+	client, svc := newTestClient(t, pseudonymization.WithStrictMode())
+	ctx := context.Background()
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = client.Pseudonymize(ctx, &pseudonymizationpb.PseudonymizeRequest{Value: result.Pseudonym})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServerRateLimited(t *testing.T) {
+	limiter := pseudonymization.NewTokenBucketLimiter(1, time.Hour)
+	client, svc := newTestClient(t, pseudonymization.WithRateLimiter(limiter))
+	ctx := context.Background()
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	_, err = client.Revert(ctx, &pseudonymizationpb.RevertRequest{EncryptedValue: result.EncryptedValue})
+	assert.NoError(t, err)
+	_, err = client.Revert(ctx, &pseudonymizationpb.RevertRequest{EncryptedValue: result.EncryptedValue})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,180 @@
+// Package http exposes a pseudonymization.Service over HTTP, so it can run as
+// a sidecar or microservice for applications not written in Go.
+//
+// Endpoints (JSON in, JSON out):
+//
+//	POST /pseudonymize  {"value": "...", "purpose": "...", "system": "..."}
+//	                    -> pseudonymization.Result
+//	POST /revert        {"encrypted_original_value": "...", "purpose": "...", "system": "..."}
+//	                    -> {"value": "..."}
+//
+// Failures are returned as {"error": {"code": "...", "message": "..."}} with
+// a status derived from the package's sentinel errors: 400 for invalid
+// requests, empty values, values that are already pseudonyms (in strict
+// mode) and anonymized (non-reversible) values, 413 for values over the
+// service's maximum length, 422 when a ciphertext fails authentication and
+// 503 once the service is closed or its key has reached its nonce limit.
+//
+// The handler performs no authentication: anyone who can reach /revert can
+// re-identify data. Deploy it behind an authenticating proxy or on a private
+// network only.
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/raywall/pseudonymization-lgpd-tools"
+)
+
+// maxBodySize bounds the size of request bodies
+const maxBodySize = 1 << 20
+
+// Error codes returned in the "code" field of error responses
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeEmptyValue           = "empty_value"
+	CodeValueTooLarge        = "value_too_large"
+	CodeAlreadyPseudonymized = "already_pseudonymized"
+	CodeMalformedCiphertext  = "malformed_ciphertext"
+	CodeNotReversible        = "not_reversible"
+	CodeDecryptionFailed     = "decryption_failed"
+	CodeRateLimited          = "rate_limited"
+	CodeServiceUnavailable   = "service_unavailable"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeInternal             = "internal_error"
+)
+
+// PseudonymizeRequest is the body of POST /pseudonymize
+type PseudonymizeRequest struct {
+	Value   string `json:"value"`
+	Purpose string `json:"purpose"`
+	System  string `json:"system"`
+}
+
+// RevertRequest is the body of POST /revert. Purpose and system are only
+// needed for services created with WithPurposeBinding.
+type RevertRequest struct {
+	EncryptedValue string `json:"encrypted_original_value"`
+	Purpose        string `json:"purpose,omitempty"`
+	System         string `json:"system,omitempty"`
+}
+
+// RevertResponse is the body of a successful POST /revert
+type RevertResponse struct {
+	Value string `json:"value"`
+}
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes a failure with a stable, machine-readable code
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Handler returns an http.Handler serving the pseudonymize and revert
+// endpoints backed by svc
+//
+// Parameters:
+// - svc: The service performing the operations
+//
+// Returns:
+// - http.Handler to mount on a server or mux
+func Handler(svc *pseudonymization.Service) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pseudonymize", func(w http.ResponseWriter, r *http.Request) {
+		var req PseudonymizeRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+
+		result, err := svc.PseudonymizeContext(r.Context(), req.Value, req.Purpose, req.System)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+	mux.HandleFunc("/revert", func(w http.ResponseWriter, r *http.Request) {
+		var req RevertRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+
+		value, err := svc.RevertWithContext(req.EncryptedValue, req.Purpose, req.System)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, RevertResponse{Value: value})
+	})
+	return mux
+}
+
+// decodeRequest checks the method and decodes the JSON body into v, writing
+// an error response and returning false on failure
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: ErrorDetail{
+			Code:    CodeMethodNotAllowed,
+			Message: "only POST is supported",
+		}})
+		return false
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: ErrorDetail{
+			Code:    CodeInvalidRequest,
+			Message: err.Error(),
+		}})
+		return false
+	}
+	return true
+}
+
+// writeError maps err to a status code and error code
+func writeError(w http.ResponseWriter, err error) {
+	status, code := http.StatusInternalServerError, CodeInternal
+	switch {
+	case errors.Is(err, pseudonymization.ErrEmptyValue):
+		status, code = http.StatusBadRequest, CodeEmptyValue
+	case errors.Is(err, pseudonymization.ErrValueTooLarge):
+		status, code = http.StatusRequestEntityTooLarge, CodeValueTooLarge
+	case errors.Is(err, pseudonymization.ErrAlreadyPseudonymized):
+		status, code = http.StatusBadRequest, CodeAlreadyPseudonymized
+	case errors.Is(err, pseudonymization.ErrMalformedCiphertext),
+		errors.Is(err, pseudonymization.ErrCiphertextTooShort):
+		status, code = http.StatusBadRequest, CodeMalformedCiphertext
+	case errors.Is(err, pseudonymization.ErrNotReversible):
+		status, code = http.StatusBadRequest, CodeNotReversible
+	case errors.Is(err, pseudonymization.ErrDecryptionFailed):
+		status, code = http.StatusUnprocessableEntity, CodeDecryptionFailed
+	case errors.Is(err, pseudonymization.ErrRateLimited):
+		status, code = http.StatusTooManyRequests, CodeRateLimited
+	case errors.Is(err, pseudonymization.ErrServiceClosed),
+		errors.Is(err, pseudonymization.ErrNonceLimitApproaching):
+		status, code = http.StatusServiceUnavailable, CodeServiceUnavailable
+	}
+
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		// Do not leak internal details
+		message = http.StatusText(status)
+	}
+	writeJSON(w, status, ErrorResponse{Error: ErrorDetail{Code: code, Message: message}})
+}
+
+// writeJSON writes v as the JSON response body with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raywall/pseudonymization-lgpd-tools"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T, opts ...pseudonymization.Option) (*httptest.Server, *pseudonymization.Service) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	svc := pseudonymization.NewService(key, opts...)
+	server := httptest.NewServer(Handler(svc))
+	t.Cleanup(server.Close)
+	return server, svc
+}
+
+func post(t *testing.T, server *httptest.Server, path, body string, v interface{}) int {
+	resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+func TestPseudonymizeAndRevert(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	var result pseudonymization.Result
+	status := post(t, server, "/pseudonymize", `{"value":"52998224725","purpose":"billing","system":"erp"}`, &result)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, result.Pseudonym)
+	assert.NotEmpty(t, result.EncryptedValue)
+
+	body, err := json.Marshal(RevertRequest{EncryptedValue: result.EncryptedValue})
+	assert.NoError(t, err)
+	var reverted RevertResponse
+	status = post(t, server, "/revert", string(body), &reverted)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "52998224725", reverted.Value)
+}
+
+func TestErrorResponses(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	// Valid base64 and long enough, but not produced by this service's key
+	forged := "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+
+	testCases := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"empty value", "/pseudonymize", `{"value":""}`, http.StatusBadRequest, CodeEmptyValue},
+		{"invalid JSON", "/pseudonymize", `{"value":`, http.StatusBadRequest, CodeInvalidRequest},
+		{"unknown field", "/pseudonymize", `{"cpf":"52998224725"}`, http.StatusBadRequest, CodeInvalidRequest},
+		{"malformed ciphertext", "/revert", `{"encrypted_original_value":"not base64!"}`, http.StatusBadRequest, CodeMalformedCiphertext},
+		{"anonymized value", "/revert", `{"encrypted_original_value":""}`, http.StatusBadRequest, CodeNotReversible},
+		{"decryption failure", "/revert", `{"encrypted_original_value":"` + forged + `"}`, http.StatusUnprocessableEntity, CodeDecryptionFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp ErrorResponse
+			status := post(t, server, tc.path, tc.body, &resp)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.code, resp.Error.Code)
+			assert.NotEmpty(t, resp.Error.Message)
+		})
+	}
+}
+
+func TestValueTooLarge(t *testing.T) {
+	server, _ := newTestServer(t, pseudonymization.WithMaxValueLength(8))
+
+	var resp ErrorResponse
+	status := post(t, server, "/pseudonymize", `{"value":"52998224725"}`, &resp)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	assert.Equal(t, CodeValueTooLarge, resp.Error.Code)
+}
+
+func TestAlreadyPseudonymized(t *testing.T) {
+	server, svc := newTestServer(t, pseudonymization.WithStrictMode())
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+
+	var resp ErrorResponse
+	status := post(t, server, "/pseudonymize", `{"value":"`+result.Pseudonym+`"}`, &resp)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, CodeAlreadyPseudonymized, resp.Error.Code)
+}
+
+func TestRateLimited(t *testing.T) {
+	limiter := pseudonymization.NewTokenBucketLimiter(1, time.Hour)
+	server, svc := newTestServer(t, pseudonymization.WithRateLimiter(limiter))
+
+	result, err := svc.Pseudonymize("52998224725", "test", "test")
+	assert.NoError(t, err)
+	body, err := json.Marshal(RevertRequest{EncryptedValue: result.EncryptedValue})
+	assert.NoError(t, err)
+
+	var reverted RevertResponse
+	assert.Equal(t, http.StatusOK, post(t, server, "/revert", string(body), &reverted))
+
+	var resp ErrorResponse
+	status := post(t, server, "/revert", string(body), &resp)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, CodeRateLimited, resp.Error.Code)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/pseudonymize")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+}
+
+func TestClosedService(t *testing.T) {
+	server, svc := newTestServer(t)
+	assert.NoError(t, svc.Close())
+
+	var resp ErrorResponse
+	status := post(t, server, "/pseudonymize", `{"value":"52998224725"}`, &resp)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, CodeServiceUnavailable, resp.Error.Code)
+}
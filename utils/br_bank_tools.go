@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+)
+
+// Bank codes (COMPE) with built-in check-digit rules
+const (
+	BankCodeBancoDoBrasil = "001"
+	BankCodeCaixa         = "104"
+	BankCodeBradesco      = "237"
+	BankCodeItau          = "341"
+)
+
+// BankAccount is an agency/account pair split into numbers and check digits
+type BankAccount struct {
+	Agency       string // Agency number, digits only
+	AgencyDigit  string // Agency check digit, empty when not given
+	Account      string // Account number, digits only
+	AccountDigit string // Account check digit (a digit, or a letter such as X or P)
+}
+
+// BankAccountValidator checks the check digits of a bank account for one
+// institution. The account has already passed the format checks of
+// IsValidBankAccount.
+type BankAccountValidator func(account BankAccount) bool
+
+var (
+	bankValidatorsMu sync.RWMutex
+	bankValidators   = map[string]BankAccountValidator{
+		BankCodeBancoDoBrasil: validateBancoDoBrasil,
+		BankCodeCaixa:         validateCaixa,
+		BankCodeBradesco:      validateBradesco,
+		BankCodeItau:          validateItau,
+	}
+)
+
+// RegisterBankAccountValidator installs the check-digit rule used by
+// IsValidBankAccount for bankCode, replacing any existing rule (including the
+// built-in ones). A nil validator removes the rule, so the bank falls back to
+// format-only validation. It is safe for concurrent use.
+//
+// Parameters:
+// - bankCode: The three-digit COMPE code of the bank (e.g. "033")
+// - validator: The check-digit function for the bank
+func RegisterBankAccountValidator(bankCode string, validator BankAccountValidator) {
+	code := normalizeBankCode(bankCode)
+
+	bankValidatorsMu.Lock()
+	defer bankValidatorsMu.Unlock()
+	if validator == nil {
+		delete(bankValidators, code)
+		return
+	}
+	bankValidators[code] = validator
+}
+
+// IsValidBankAccount checks if an agency/account pair is valid for a
+// Brazilian bank
+// Every institution computes its check digits differently, so the rule is
+// chosen by bank code. Built-in rules cover Banco do Brasil (001), Caixa
+// (104), Bradesco (237) and Itaú (341); other banks can be added with
+// RegisterBankAccountValidator. Banks without a rule only get format
+// validation: numeric agency and account with an account check digit.
+//
+// The check digit is separated by a hyphen ("1234-5", "12345678-X"). An
+// account without a hyphen is read with its last character as check digit;
+// an agency without a hyphen has no check digit. Spaces and dots are ignored.
+//
+// Parameters:
+// - bankCode: The COMPE code of the bank ("1" and "001" are equivalent)
+// - agency: The agency number, with optional check digit
+// - account: The account number with check digit
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidBankAccount(bankCode, agency, account string) bool {
+	parsed, ok := parseBankAccount(agency, account)
+	if !ok {
+		return false
+	}
+
+	bankValidatorsMu.RLock()
+	validator, ok := bankValidators[normalizeBankCode(bankCode)]
+	bankValidatorsMu.RUnlock()
+	if !ok {
+		return true
+	}
+	return validator(parsed)
+}
+
+// Banco do Brasil: agency of 4 digits and account of up to 8 digits, both
+// mod 11 with weights counting up from 2 on the right; a result of 10 is X
+// and 11 is 0
+func validateBancoDoBrasil(account BankAccount) bool {
+	if len(account.Agency) > 4 || len(account.Account) > 8 {
+		return false
+	}
+	if account.AgencyDigit != "" && account.AgencyDigit != bankMod11Digit(account.Agency, 9, "X") {
+		return false
+	}
+	return account.AccountDigit == bankMod11Digit(account.Account, 9, "X")
+}
+
+// Bradesco: agency of 4 digits and account of up to 7 digits, both mod 11
+// with weights counting up from 2 on the right, up to 7 for the account
+// (2, 7, 6, 5, 4, 3, 2 from the left); a result of 10 is P and 11 is 0
+func validateBradesco(account BankAccount) bool {
+	if len(account.Agency) > 4 || len(account.Account) > 7 {
+		return false
+	}
+	if account.AgencyDigit != "" && account.AgencyDigit != bankMod11Digit(account.Agency, 9, "P") {
+		return false
+	}
+	return account.AccountDigit == bankMod11Digit(account.Account, 7, "P")
+}
+
+// Itaú: agency of 4 digits without check digit and account of 5 digits. The
+// check digit is mod 10 over agency and account, with weights alternating
+// 2 and 1 from the left and the digits of each product summed.
+func validateItau(account BankAccount) bool {
+	if len(account.Agency) > 4 || account.AgencyDigit != "" || len(account.Account) > 5 {
+		return false
+	}
+
+	digits := leftPad(account.Agency, 4) + leftPad(account.Account, 5)
+	var sum int
+	for i, c := range digits {
+		product := int(c-'0') * (2 - i%2)
+		sum += product/10 + product%10
+	}
+	return account.AccountDigit == string(rune('0'+(10-sum%10)%10))
+}
+
+// Caixa: agency of 4 digits without check digit and account of 11 digits
+// (3-digit operation code followed by the 8-digit number). The check digit is
+// the sum over agency and account with weights 8 to 2 then 9 to 2, times 10,
+// mod 11; a result of 10 is 0.
+func validateCaixa(account BankAccount) bool {
+	if len(account.Agency) > 4 || account.AgencyDigit != "" || len(account.Account) > 11 {
+		return false
+	}
+
+	weights := [15]int{8, 7, 6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	digits := leftPad(account.Agency, 4) + leftPad(account.Account, 11)
+	var sum int
+	for i, c := range digits {
+		sum += int(c-'0') * weights[i]
+	}
+
+	digit := sum * 10 % 11
+	if digit == 10 {
+		digit = 0
+	}
+	return account.AccountDigit == string(rune('0'+digit))
+}
+
+// Helper function to compute a mod 11 check digit with weights 2, 3, ...
+// maxWeight from the rightmost digit, restarting at 2 after maxWeight. A
+// result of 10 is replaced by ten, and 11 by 0.
+func bankMod11Digit(number string, maxWeight int, ten string) string {
+	var sum int
+	weight := 2
+	for i := len(number) - 1; i >= 0; i-- {
+		sum += int(number[i]-'0') * weight
+		if weight++; weight > maxWeight {
+			weight = 2
+		}
+	}
+
+	switch digit := 11 - sum%11; digit {
+	case 10:
+		return ten
+	case 11:
+		return "0"
+	default:
+		return string(rune('0' + digit))
+	}
+}
+
+// Helper function to split agency and account into numbers and check digits
+// and validate their format
+func parseBankAccount(agency, account string) (BankAccount, bool) {
+	var parsed BankAccount
+	var ok bool
+
+	if parsed.Agency, parsed.AgencyDigit, ok = splitBankCheckDigit(agency, false); !ok {
+		return BankAccount{}, false
+	}
+	if parsed.Account, parsed.AccountDigit, ok = splitBankCheckDigit(account, true); !ok {
+		return BankAccount{}, false
+	}
+	if parsed.AccountDigit == "" || len(parsed.Agency) > 5 || len(parsed.Account) > 13 {
+		return BankAccount{}, false
+	}
+	return parsed, true
+}
+
+// Helper function to split "number-digit". Without a hyphen the last
+// character is taken as check digit only when implicit is true.
+func splitBankCheckDigit(value string, implicit bool) (number, digit string, ok bool) {
+	value = strings.ToUpper(strings.NewReplacer(" ", "", ".", "").Replace(value))
+
+	if i := strings.LastIndexByte(value, '-'); i >= 0 {
+		number, digit = value[:i], value[i+1:]
+		if len(digit) != 1 {
+			return "", "", false
+		}
+	} else if implicit && len(value) > 1 {
+		number, digit = value[:len(value)-1], value[len(value)-1:]
+	} else {
+		number = value
+	}
+
+	if number == "" || cleanDigits(number) != number {
+		return "", "", false
+	}
+	if digit != "" && !(digit[0] >= '0' && digit[0] <= '9' || digit[0] >= 'A' && digit[0] <= 'Z') {
+		return "", "", false
+	}
+	return number, digit, true
+}
+
+// Helper function to normalize a bank code to three digits
+func normalizeBankCode(bankCode string) string {
+	return leftPad(cleanDigits(bankCode), 3)
+}
+
+// Helper function to left-pad a digit string with zeros to size
+func leftPad(digits string, size int) string {
+	if len(digits) >= size {
+		return digits
+	}
+	return strings.Repeat("0", size-len(digits)) + digits
+}
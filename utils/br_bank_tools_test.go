@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankAccountValidation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bankCode string
+		agency   string
+		account  string
+		isValid  bool
+	}{
+		{"BB", "001", "1234-3", "00012345-5", true},
+		{"BB short bank code", "1", "1234", "12345-5", true},
+		{"BB unformatted account", "001", "1234", "000123455", true},
+		{"BB X check digit", "001", "1234", "00123403-X", true},
+		{"BB lower-case x", "001", "1234", "00123403-x", true},
+		{"BB wrong agency digit", "001", "1234-4", "00012345-5", false},
+		{"BB wrong account digit", "001", "1234-3", "00012345-6", false},
+		{"BB account too long", "001", "1234", "123456789-0", false},
+		{"Bradesco", "237", "1425-7", "0238069-2", true},
+		{"Bradesco P check digit", "237", "1425", "0000104-P", true},
+		{"Bradesco wrong account digit", "237", "1425-7", "0238069-3", false},
+		{"Bradesco leading non-zero digit", "237", "1425", "1234567-4", true},
+		{"Bradesco weight 8 not used", "237", "1425", "1234567-9", false},
+		{"Itaú", "341", "2545", "02366-1", true},
+		{"Itaú formatted", "341", "0341", "12.345-3", true},
+		{"Itaú wrong digit", "341", "2545", "02366-2", false},
+		{"Itaú agency digit not allowed", "341", "2545-1", "02366-1", false},
+		{"Caixa", "104", "0001", "001.00000448-4", true},
+		{"Caixa other account", "104", "1234", "01300012345-2", true},
+		{"Caixa wrong digit", "104", "0001", "00100000448-5", false},
+		{"Unknown bank format only", "033", "1234", "1234567-8", true},
+		{"Unknown bank non-numeric", "033", "12a4", "1234567-8", false},
+		{"Missing account digit", "033", "1234", "1", false},
+		{"Empty agency", "001", "", "00012345-5", false},
+		{"Empty account", "001", "1234", "", false},
+		{"Double hyphen digit", "001", "1234", "00012345-55", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidBankAccount(tc.bankCode, tc.agency, tc.account))
+		})
+	}
+}
+
+func TestRegisterBankAccountValidator(t *testing.T) {
+	defer RegisterBankAccountValidator("077", nil)
+
+	// Format-only until a rule is registered
+	assert.True(t, IsValidBankAccount("077", "0001", "1234567-0"))
+
+	RegisterBankAccountValidator("77", func(account BankAccount) bool {
+		return account.Agency == "0001" && account.AccountDigit == "1"
+	})
+	assert.False(t, IsValidBankAccount("077", "0001", "1234567-0"))
+	assert.True(t, IsValidBankAccount("077", "0001", "1234567-1"))
+
+	RegisterBankAccountValidator("077", nil)
+	assert.True(t, IsValidBankAccount("077", "0001", "1234567-0"))
+}
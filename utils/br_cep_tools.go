@@ -0,0 +1,48 @@
+package utils
+
+import "errors"
+
+// ErrInvalidCEP is returned when a string is not a valid CEP
+var ErrInvalidCEP = errors.New("invalid CEP")
+
+// lowestCEP is the smallest CEP in use (01000-000, São Paulo); anything below
+// it, such as 00000-000, is a placeholder rather than a real address
+const lowestCEP = "01000000"
+
+// IsValidCEP checks if a string is a valid CEP (Brazilian postal code)
+// It removes formatting characters and checks the 8-digit format
+//
+// Parameters:
+// - cep: The CEP string to validate (e.g. 01310-100 or 01310100)
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidCEP(cep string) bool {
+	// Remove all non-digit characters
+	cleaned := cleanDigits(cep)
+
+	// Check length (must be 8 digits)
+	if len(cleaned) != 8 {
+		return false
+	}
+
+	// Reject placeholder values such as 00000-000
+	return cleaned >= lowestCEP
+}
+
+// NormalizeCEP returns a CEP in its canonical NNNNN-NNN form
+//
+// Parameters:
+// - cep: The CEP string to normalize (formatted or unformatted)
+//
+// Returns:
+// - string: The CEP formatted as NNNNN-NNN
+// - error: ErrInvalidCEP if cep is not a valid CEP
+func NormalizeCEP(cep string) (string, error) {
+	if !IsValidCEP(cep) {
+		return "", ErrInvalidCEP
+	}
+
+	cleaned := cleanDigits(cep)
+	return cleaned[:5] + "-" + cleaned[5:], nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCEPValidation(t *testing.T) {
+	testCases := []struct {
+		cep     string
+		isValid bool
+	}{
+		{"01310-100", true},  // Valid formatted CEP
+		{"01310100", true},   // Valid unformatted CEP
+		{"99999-999", true},  // Highest CEP
+		{"00000-000", false}, // Placeholder
+		{"00999-999", false}, // Below the lowest CEP in use
+		{"1310-100", false},  // Too short
+		{"013101000", false}, // Too long
+		{"", false},          // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cep, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidCEP(tc.cep))
+		})
+	}
+}
+
+func TestNormalizeCEP(t *testing.T) {
+	normalized, err := NormalizeCEP("01310100")
+	assert.NoError(t, err)
+	assert.Equal(t, "01310-100", normalized)
+
+	normalized, err = NormalizeCEP(" 01.310-100 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "01310-100", normalized)
+
+	_, err = NormalizeCEP("00000-000")
+	assert.ErrorIs(t, err, ErrInvalidCEP)
+}
@@ -0,0 +1,71 @@
+package utils
+
+// IsValidCNH checks if a string is a valid CNH (driver's license) number
+// It removes formatting characters and validates the two check digits
+//
+// The first check digit is the sum of the first nine digits weighted 9 down
+// to 1, mod 11; a result of 10 or more becomes 0 and sets a discount (dsc)
+// of 2. The second check digit is the sum of the same digits weighted 1 up
+// to 9, mod 11; a result of 10 or more becomes 0, otherwise dsc is
+// subtracted from it. Numbers whose second digit would be negative are
+// invalid.
+//
+// Parameters:
+// - cnh: The CNH string to validate (can include formatting characters)
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidCNH(cnh string) bool {
+	// Remove all non-digit characters
+	cleaned := cleanDigits(cnh)
+
+	// Check length (must be 11 digits)
+	if len(cleaned) != 11 {
+		return false
+	}
+
+	// Check for invalid patterns (all digits same)
+	if allDigitsSame(cleaned) {
+		return false
+	}
+
+	firstDigit, secondDigit, ok := calculateCNHCheckDigits(cleaned[:9])
+	if !ok {
+		return false
+	}
+
+	// Verify check digits
+	return cleaned[9] == firstDigit && cleaned[10] == secondDigit
+}
+
+// Helper function to calculate both CNH check digits; ok is false when the
+// base number has no valid second check digit
+func calculateCNHCheckDigits(base string) (first, second byte, ok bool) {
+	var sum, dsc int
+	for i, c := range base {
+		sum += int(c-'0') * (9 - i)
+	}
+
+	firstValue := sum % 11
+	if firstValue >= 10 {
+		firstValue = 0
+		dsc = 2
+	}
+
+	sum = 0
+	for i, c := range base {
+		sum += int(c-'0') * (i + 1)
+	}
+
+	secondValue := sum % 11
+	if secondValue >= 10 {
+		secondValue = 0
+	} else {
+		secondValue -= dsc
+	}
+	if secondValue < 0 {
+		return 0, 0, false
+	}
+
+	return byte('0' + firstValue), byte('0' + secondValue), true
+}
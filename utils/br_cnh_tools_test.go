@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCNHValidation(t *testing.T) {
+	testCases := []struct {
+		cnh     string
+		isValid bool
+	}{
+		{"52601815980", true},    // Valid CNH
+		{"08301661350", true},    // Valid CNH with leading zero
+		{"18609139043", true},    // Valid CNH
+		{"62819482121", true},    // Valid CNH
+		{"526.018.159-80", true}, // Valid formatted CNH
+		{"76842684603", true},    // Valid CNH with dsc offset applied
+		{"35379907506", true},    // Valid CNH with dsc offset applied
+		{"52601815981", false},   // Wrong second check digit
+		{"52601815990", false},   // Wrong first check digit
+		{"47824504000", false},   // dsc offset makes the second digit negative
+		{"11111111111", false},   // Invalid (all same digits)
+		{"", false},              // Empty
+		{"5260181598", false},    // Too short
+		{"526018159800", false},  // Too long
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cnh, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidCNH(tc.cnh))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// IsValidCNPJ checks if a string is a valid CNPJ number according to Brazilian rules
+// It removes formatting characters and validates the check digits
+//
+// Parameters:
+// - cnpj: The CNPJ string to validate (can include formatting like ., / and -)
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidCNPJ(cnpj string) bool {
+	// Remove all non-digit characters
+	cleaned := cleanDigits(cnpj)
+
+	// Check length (must be 14 digits)
+	if len(cleaned) != 14 {
+		return false
+	}
+
+	// Check for invalid patterns (all digits same)
+	if allDigitsSame(cleaned) {
+		return false
+	}
+
+	// Calculate first check digit
+	firstDigit := calculateCNPJCheckDigit(cleaned[:12])
+
+	// Calculate second check digit
+	secondDigit := calculateCNPJCheckDigit(cleaned[:13])
+
+	// Verify check digits
+	return cleaned[12] == firstDigit && cleaned[13] == secondDigit
+}
+
+// GenerateSyntheticCNPJ creates a valid synthetic CNPJ for testing purposes
+// The generated CNPJ follows the same validation rules as real CNPJs but uses
+// a known branch number to indicate it's synthetic (branch 9999)
+//
+// Returns:
+// - string: A valid synthetic CNPJ (with formatting)
+// - error: Only returns error if random number generation fails
+func GenerateSyntheticCNPJ() (string, error) {
+	return GenerateSyntheticCNPJFromReader(rand.Reader)
+}
+
+// GenerateSyntheticCNPJFromReader is GenerateSyntheticCNPJ drawing its random
+// digits from random instead of crypto/rand, so tests can pass a
+// deterministic reader and get reproducible CNPJs
+//
+// Parameters:
+// - random: Source of randomness
+//
+// Returns:
+// - string: A valid synthetic CNPJ (with formatting)
+// - error: Only returns error if reading from random fails
+func GenerateSyntheticCNPJFromReader(random io.Reader) (string, error) {
+	// Use 9999 as branch to clearly identify synthetic CNPJs
+	branch := "9999"
+
+	// Generate 8 random digits for the company root
+	randomDigits := make([]byte, 8)
+	_, err := io.ReadFull(random, randomDigits)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate random digits: %w", err)
+	}
+
+	// Convert to digits 0-9
+	for i := range randomDigits {
+		randomDigits[i] = '0' + (randomDigits[i] % 10)
+	}
+
+	// Combine root and branch (12 digits total)
+	partialCNPJ := string(randomDigits) + branch
+
+	// Calculate first check digit
+	firstDigit := calculateCNPJCheckDigit(partialCNPJ)
+	partialCNPJ += string(firstDigit)
+
+	// Calculate second check digit
+	secondDigit := calculateCNPJCheckDigit(partialCNPJ)
+	fullCNPJ := partialCNPJ + string(secondDigit)
+
+	// Format with standard CNPJ punctuation
+	return formatCNPJ(fullCNPJ), nil
+}
+
+// FormatCNPJ formats a CNPJ with standard punctuation (NN.NNN.NNN/NNNN-NN).
+// Formatted and unformatted input are handled alike; values that do not
+// contain exactly 14 digits are returned unchanged. Check digits are not
+// verified; use IsValidCNPJ for that.
+//
+// Parameters:
+// - cnpj: The CNPJ to format
+//
+// Returns:
+// - string: The formatted CNPJ, or cnpj itself if it is not 14 digits
+func FormatCNPJ(cnpj string) string {
+	cleaned := cleanDigits(cnpj)
+	if len(cleaned) != 14 {
+		return cnpj
+	}
+	return formatCNPJ(cleaned)
+}
+
+// Helper function to calculate CNPJ check digit
+// Weights start at len-7 and decrease, cycling from 2 back to 9
+// (5,4,3,2,9,...,2 for the first digit and 6,5,4,3,2,9,...,2 for the second)
+func calculateCNPJCheckDigit(partialCNPJ string) byte {
+	var sum int
+	weight := len(partialCNPJ) - 7
+	for _, c := range partialCNPJ {
+		sum += int(c-'0') * weight
+		weight--
+		if weight < 2 {
+			weight = 9
+		}
+	}
+
+	remainder := sum % 11
+	if remainder < 2 {
+		return '0'
+	}
+	return byte('0' + (11 - remainder))
+}
+
+// Helper function to format CNPJ with standard punctuation
+func formatCNPJ(cnpj string) string {
+	if len(cnpj) != 14 {
+		return cnpj
+	}
+	return fmt.Sprintf("%s.%s.%s/%s-%s", cnpj[:2], cnpj[2:5], cnpj[5:8], cnpj[8:12], cnpj[12:])
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCNPJValidation(t *testing.T) {
+	testCases := []struct {
+		cnpj    string
+		isValid bool
+	}{
+		{"11.222.333/0001-81", true},  // Valid formatted CNPJ
+		{"11222333000181", true},      // Valid unformatted CNPJ
+		{"45.284.783/0001-10", true},  // Valid formatted CNPJ
+		{"11.111.111/1111-11", false}, // Invalid (all same digits)
+		{"11.222.333/0001-82", false}, // Invalid (one wrong digit)
+		{"12.345.678/0001-00", false}, // Invalid (wrong check digits)
+		{"", false},                   // Empty
+		{"1122233300018", false},      // Too short
+		{"112223330001811", false},    // Too long
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cnpj, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidCNPJ(tc.cnpj))
+		})
+	}
+}
+
+func TestSyntheticCNPJGeneration(t *testing.T) {
+	// Test multiple generations
+	for i := 0; i < 100; i++ {
+		cnpj, err := GenerateSyntheticCNPJ()
+		assert.NoError(t, err)
+		assert.Equal(t, "9999", cleanDigits(cnpj)[8:12])
+		assert.True(t, strings.Contains(cnpj, "/9999-"))
+		assert.True(t, IsValidCNPJ(cnpj))
+	}
+}
+
+func TestSyntheticCNPJFromReader(t *testing.T) {
+	cnpj, err := GenerateSyntheticCNPJFromReader(bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 18}))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(cnpj, "12.345.678/9999-"), cnpj)
+	assert.True(t, IsValidCNPJ(cnpj))
+
+	_, err = GenerateSyntheticCNPJFromReader(failingReader{})
+	assert.Error(t, err)
+}
+
+func TestFormatCNPJ(t *testing.T) {
+	testCases := []struct {
+		cnpj     string
+		expected string
+	}{
+		{"11222333000181", "11.222.333/0001-81"},     // Unformatted
+		{"11.222.333/0001-81", "11.222.333/0001-81"}, // Already formatted
+		{"11 222 333 0001 81", "11.222.333/0001-81"}, // Other separators
+		{"1122233300018", "1122233300018"},           // Too short: unchanged
+		{"", ""},                                     // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cnpj, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatCNPJ(tc.cnpj))
+		})
+	}
+}
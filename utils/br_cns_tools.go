@@ -0,0 +1,81 @@
+package utils
+
+import "fmt"
+
+// IsValidCNS checks if a string is a valid CNS (Cartão Nacional de Saúde)
+// It removes formatting characters and validates the 15 digits by the rule
+// of their first digit:
+//   - 1 or 2 (definitive cards): the first 11 digits are the holder's
+//     PIS/PASEP, followed by "000" and a mod-11 check digit of the PIS
+//     weighted 15 down to 5, or by "001" and a recomputed check digit when
+//     the first one would be 10
+//   - 7, 8 or 9 (provisional cards): the sum of all 15 digits weighted 15
+//     down to 1 is divisible by 11
+//
+// Numbers starting with any other digit are invalid.
+//
+// Parameters:
+// - cns: The CNS string to validate (can include spaces, e.g. 123 4567 8901 0000)
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidCNS(cns string) bool {
+	// Remove all non-digit characters
+	cleaned := cleanDigits(cns)
+
+	// Check length (must be 15 digits)
+	if len(cleaned) != 15 {
+		return false
+	}
+
+	switch cleaned[0] {
+	case '1', '2':
+		return cleaned == definitiveCNS(cleaned[:11])
+	case '7', '8', '9':
+		return cnsWeightedSum(cleaned)%11 == 0
+	default:
+		return false
+	}
+}
+
+// FormatCNS formats a CNS as NNN NNNN NNNN NNNN, the grouping printed on the
+// card. Input that does not contain exactly 15 digits is returned unchanged;
+// check digits are not verified, use IsValidCNS for that.
+//
+// Parameters:
+// - cns: The CNS (formatted or unformatted)
+//
+// Returns:
+// - string: The formatted CNS
+func FormatCNS(cns string) string {
+	cleaned := cleanDigits(cns)
+	if len(cleaned) != 15 {
+		return cns
+	}
+	return fmt.Sprintf("%s %s %s %s", cleaned[:3], cleaned[3:7], cleaned[7:11], cleaned[11:])
+}
+
+// Helper function to build the definitive CNS for an 11-digit PIS
+func definitiveCNS(pis string) string {
+	sum := cnsWeightedSum(pis)
+	digit := 11 - sum%11
+	if digit == 11 {
+		digit = 0
+	}
+	if digit != 10 {
+		return fmt.Sprintf("%s000%d", pis, digit)
+	}
+
+	// The "001" infix adds 1 * 2 to the weighted sum
+	digit = 11 - (sum+2)%11
+	return fmt.Sprintf("%s001%d", pis, digit)
+}
+
+// Helper function to weigh digits 15, 14, ... from the left and sum them
+func cnsWeightedSum(digits string) int {
+	var sum int
+	for i, c := range digits {
+		sum += int(c-'0') * (15 - i)
+	}
+	return sum
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCNSValidation(t *testing.T) {
+	testCases := []struct {
+		cns     string
+		isValid bool
+	}{
+		{"123 4567 8901 0000", true}, // Definitive, formatted
+		{"123456789010000", true},    // Definitive, unformatted
+		{"298765432100018", true},    // Definitive, "001" infix
+		{"286981984630018", true},    // Definitive, "001" infix
+		{"170000000000008", true},    // Definitive
+		{"700123456789010", true},    // Provisional starting with 7
+		{"800 1234 5678 9017", true}, // Provisional starting with 8
+		{"900123456789013", true},    // Provisional starting with 9
+		{"123456789010001", false},   // Wrong check digit
+		{"298765432100008", false},   // "000" where "001" is required
+		{"123456789010010", false},   // "001" where "000" is required
+		{"800123456789018", false},   // Provisional, wrong sum
+		{"300123456789013", false},   // Invalid first digit
+		{"000000000000000", false},   // Invalid first digit
+		{"12345678901000", false},    // Too short
+		{"1234567890100000", false},  // Too long
+		{"", false},                  // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cns, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidCNS(tc.cns))
+		})
+	}
+}
+
+func TestFormatCNS(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"123456789010000", "123 4567 8901 0000"},
+		{"123 4567 8901 0000", "123 4567 8901 0000"},
+		{"700.1234.5678.9010", "700 1234 5678 9010"},
+		{"12345", "12345"}, // Wrong length is returned unchanged
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatCNS(tc.input))
+		})
+	}
+}
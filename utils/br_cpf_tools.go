@@ -2,9 +2,16 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 )
 
+// ErrInvalidCPF is returned when a string is not a valid CPF
+var ErrInvalidCPF = errors.New("invalid CPF")
+
 // IsValidCPF checks if a string is a valid CPF number according to Brazilian rules
 // It removes formatting characters and validates the check digits
 //
@@ -45,12 +52,23 @@ func IsValidCPF(cpf string) bool {
 // - string: A valid synthetic CPF (with formatting)
 // - error: Only returns error if random number generation fails
 func GenerateSyntheticCPF() (string, error) {
-	// Use 999 as prefix to clearly identify synthetic CPFs
-	prefix := "999"
+	return GenerateSyntheticCPFFromReader(rand.Reader)
+}
 
+// GenerateSyntheticCPFFromReader is GenerateSyntheticCPF drawing its random
+// digits from random instead of crypto/rand, so tests can pass a
+// deterministic reader and get reproducible CPFs
+//
+// Parameters:
+// - random: Source of randomness
+//
+// Returns:
+// - string: A valid synthetic CPF (with formatting)
+// - error: Only returns error if reading from random fails
+func GenerateSyntheticCPFFromReader(random io.Reader) (string, error) {
 	// Generate 6 random digits
 	randomDigits := make([]byte, 6)
-	_, err := rand.Read(randomDigits)
+	_, err := io.ReadFull(random, randomDigits)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate random digits: %w", err)
 	}
@@ -60,8 +78,66 @@ func GenerateSyntheticCPF() (string, error) {
 		randomDigits[i] = '0' + (randomDigits[i] % 10)
 	}
 
-	// Combine prefix and random digits (9 digits total)
-	partialCPF := prefix + string(randomDigits)
+	return syntheticCPF(string(randomDigits)), nil
+}
+
+// GenerateSyntheticCPFFromSeed creates a synthetic CPF derived from seed, so
+// the same seed always yields the same CPF. It suits reproducible fixtures
+// and golden tests; use GenerateSyntheticCPF when the CPF must not be
+// predictable. The six body digits come from the SHA-256 of the seed, so
+// different seeds can collide (one in a million per pair).
+//
+// Parameters:
+// - seed: Any string identifying the fixture
+//
+// Returns:
+// - string: A valid synthetic CPF with prefix 999 (with formatting)
+func GenerateSyntheticCPFFromSeed(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	// Body 999999 would give 999.999.999-99, rejected as repeated digits
+	body := binary.BigEndian.Uint64(sum[:8]) % 999999
+	return syntheticCPF(fmt.Sprintf("%06d", body))
+}
+
+// cpfRegions lists, by the ninth digit of a CPF, the states of the Receita
+// Federal fiscal region that issued it
+var cpfRegions = [10]string{
+	"RS",
+	"DF/GO/MS/MT/TO",
+	"AC/AM/AP/PA/RO/RR",
+	"CE/MA/PI",
+	"AL/PB/PE/RN",
+	"BA/SE",
+	"MG",
+	"ES/RJ",
+	"SP",
+	"PR/SC",
+}
+
+// CPFRegion returns the fiscal region that issued a CPF, encoded in its ninth
+// digit, as the slash-separated abbreviations of its states (e.g. "SP" for 8,
+// "DF/GO/MS/MT/TO" for 1). The region can be kept as a coarse geographic
+// attribute for analytics when the CPF itself is pseudonymized. It reflects
+// where the CPF was first issued, not where the holder lives today, and is
+// meaningless for synthetic CPFs.
+//
+// Parameters:
+// - cpf: The CPF string (can include formatting like . and -)
+//
+// Returns:
+// - string: States of the issuing region
+// - error: ErrInvalidCPF if cpf is not a valid CPF
+func CPFRegion(cpf string) (string, error) {
+	if !IsValidCPF(cpf) {
+		return "", ErrInvalidCPF
+	}
+	return cpfRegions[cleanCPF(cpf)[8]-'0'], nil
+}
+
+// Helper function to build a formatted synthetic CPF from six body digits
+func syntheticCPF(body string) string {
+	// Use 999 as prefix to clearly identify synthetic CPFs
+	partialCPF := "999" + body
 
 	// Calculate first check digit
 	firstDigit := calculateCPFCheckDigit(partialCPF, 10)
@@ -72,7 +148,7 @@ func GenerateSyntheticCPF() (string, error) {
 	fullCPF := partialCPF + string(secondDigit)
 
 	// Format with standard CPF punctuation
-	return formatCPF(fullCPF), nil
+	return formatCPF(fullCPF)
 }
 
 // Helper function to calculate CPF check digit
@@ -92,13 +168,7 @@ func calculateCPFCheckDigit(partialCPF string, weight int) byte {
 
 // Helper function to remove all non-digit characters from CPF
 func cleanCPF(cpf string) string {
-	var cleaned []rune
-	for _, c := range cpf {
-		if c >= '0' && c <= '9' {
-			cleaned = append(cleaned, c)
-		}
-	}
-	return string(cleaned)
+	return cleanDigits(cpf)
 }
 
 // Helper function to check if all digits are the same
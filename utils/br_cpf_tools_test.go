@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"strings"
 	"testing"
 
@@ -38,3 +40,64 @@ func TestSyntheticCPFGeneration(t *testing.T) {
 		assert.True(t, IsValidCPF(cpf))
 	}
 }
+
+func TestSyntheticCPFFromReader(t *testing.T) {
+	// Random bytes become digits modulo 10
+	cpf, err := GenerateSyntheticCPFFromReader(bytes.NewReader([]byte{0, 11, 22, 33, 44, 55}))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(cpf, "999.012.345-"), cpf)
+	assert.True(t, IsValidCPF(cpf))
+
+	// A short source is an error, not a CPF with missing digits
+	_, err = GenerateSyntheticCPFFromReader(bytes.NewReader([]byte{1, 2, 3}))
+	assert.Error(t, err)
+	_, err = GenerateSyntheticCPFFromReader(failingReader{})
+	assert.Error(t, err)
+}
+
+// failingReader is an entropy source whose every read fails
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy source unavailable")
+}
+
+func TestCPFRegion(t *testing.T) {
+	testCases := []struct {
+		cpf    string
+		region string
+		err    error
+	}{
+		{"529.982.247-25", "ES/RJ", nil},
+		{"52998224725", "ES/RJ", nil},
+		{"111.444.777-35", "ES/RJ", nil},
+		{"123.456.789-09", "PR/SC", nil},
+		{"000.000.001-91", "DF/GO/MS/MT/TO", nil},
+		{"000.000.008-68", "SP", nil},
+		{"123.456.789-00", "", ErrInvalidCPF},
+		{"", "", ErrInvalidCPF},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cpf, func(t *testing.T) {
+			region, err := CPFRegion(tc.cpf)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.region, region)
+		})
+	}
+}
+
+func TestSyntheticCPFFromSeed(t *testing.T) {
+	testCases := []string{"", "fixture-1", "fixture-2", "maria@example.com", "João"}
+
+	for _, seed := range testCases {
+		t.Run(seed, func(t *testing.T) {
+			cpf := GenerateSyntheticCPFFromSeed(seed)
+			assert.True(t, strings.HasPrefix(cleanCPF(cpf), "999"))
+			assert.True(t, IsValidCPF(cpf))
+			assert.Equal(t, cpf, GenerateSyntheticCPFFromSeed(seed))
+		})
+	}
+
+	assert.NotEqual(t, GenerateSyntheticCPFFromSeed("fixture-1"), GenerateSyntheticCPFFromSeed("fixture-2"))
+}
@@ -0,0 +1,85 @@
+package utils
+
+import "regexp"
+
+// DocumentType identifies the kind of a Brazilian document number
+type DocumentType int
+
+const (
+	// DocumentUnknown is a value that is not a valid document of any known type
+	DocumentUnknown DocumentType = iota
+	// DocumentCPF is an individual taxpayer number (11 digits)
+	DocumentCPF
+	// DocumentCNPJ is a company taxpayer number (14 digits)
+	DocumentCNPJ
+	// DocumentCNH is a driver's license number (11 digits)
+	DocumentCNH
+)
+
+// String returns the name of the document type
+func (t DocumentType) String() string {
+	switch t {
+	case DocumentCPF:
+		return "CPF"
+	case DocumentCNPJ:
+		return "CNPJ"
+	case DocumentCNH:
+		return "CNH"
+	default:
+		return "unknown"
+	}
+}
+
+// formattedCPFPattern matches the standard CPF punctuation (NNN.NNN.NNN-NN)
+var formattedCPFPattern = regexp.MustCompile(`^\d{3}\.\d{3}\.\d{3}-\d{2}$`)
+
+// DetectDocumentType classifies a document number as CPF, CNPJ or CNH by its
+// length and check digits. Formatting characters are ignored, except that the
+// standard CPF punctuation marks the value as a CPF.
+//
+// CPF and CNH numbers both have 11 digits, and some numbers pass the check
+// digits of both. Such a number is reported as DocumentCPF, the more common
+// type, with confident set to false; callers that need certainty should use
+// the field the value came from instead.
+//
+// Parameters:
+// - doc: The document number, formatted or unformatted
+//
+// Returns:
+//   - DocumentType: The detected type, or DocumentUnknown if doc is not a valid
+//     document of any supported type
+//   - bool: true if only one type matches
+func DetectDocumentType(doc string) (DocumentType, bool) {
+	cleaned := cleanDigits(doc)
+
+	switch len(cleaned) {
+	case 14:
+		if IsValidCNPJ(cleaned) {
+			return DocumentCNPJ, true
+		}
+	case 11:
+		cpf, cnh := IsValidCPF(cleaned), IsValidCNH(cleaned)
+		switch {
+		case cpf && cnh:
+			return DocumentCPF, formattedCPFPattern.MatchString(doc)
+		case cpf:
+			return DocumentCPF, true
+		case cnh:
+			return DocumentCNH, true
+		}
+	}
+	return DocumentUnknown, false
+}
+
+// ValidateDocument checks if a string is a valid CPF, CNPJ or CNH, using the
+// validator that matches its length and check digits
+//
+// Parameters:
+// - doc: The document number, formatted or unformatted
+//
+// Returns:
+// - bool: true if doc is valid for at least one supported type
+func ValidateDocument(doc string) bool {
+	docType, _ := DetectDocumentType(doc)
+	return docType != DocumentUnknown
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDocumentType(t *testing.T) {
+	testCases := []struct {
+		doc       string
+		docType   DocumentType
+		confident bool
+	}{
+		{"529.982.247-25", DocumentCPF, true},      // Formatted CPF
+		{"52998224725", DocumentCPF, true},         // Unformatted CPF
+		{"11.222.333/0001-81", DocumentCNPJ, true}, // Formatted CNPJ
+		{"11222333000181", DocumentCNPJ, true},     // Unformatted CNPJ
+		{"10000000091", DocumentCNH, true},         // CNH only
+		{"10000001333", DocumentCPF, false},        // Valid as CPF and CNH
+		{"100.000.013-33", DocumentCPF, true},      // CPF punctuation settles it
+		{"52998224724", DocumentUnknown, false},    // Wrong check digits
+		{"11222333000182", DocumentUnknown, false}, // Wrong CNPJ check digit
+		{"11111111111", DocumentUnknown, false},    // Repeated digits
+		{"12345", DocumentUnknown, false},          // Wrong length
+		{"", DocumentUnknown, false},               // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			docType, confident := DetectDocumentType(tc.doc)
+			assert.Equal(t, tc.docType, docType)
+			assert.Equal(t, tc.confident, confident)
+			assert.Equal(t, tc.docType != DocumentUnknown, ValidateDocument(tc.doc))
+		})
+	}
+}
+
+func TestDocumentTypeString(t *testing.T) {
+	assert.Equal(t, "CPF", DocumentCPF.String())
+	assert.Equal(t, "CNPJ", DocumentCNPJ.String())
+	assert.Equal(t, "CNH", DocumentCNH.String())
+	assert.Equal(t, "unknown", DocumentUnknown.String())
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidNFeKey is returned when a string is not a valid NF-e access key
+var ErrInvalidNFeKey = errors.New("invalid NF-e access key")
+
+// nfeKeyLength is the number of digits in an NF-e access key (chave de acesso)
+const nfeKeyLength = 44
+
+// NFeKeyParts holds the fields of an NF-e (or NFC-e) access key. Numeric
+// codes are kept as strings to preserve their leading zeros.
+type NFeKeyParts struct {
+	UF           string // IBGE code of the issuer's state, e.g. "35" for São Paulo
+	Year         int    // Year of issue, e.g. 2024
+	Month        int    // Month of issue, 1-12
+	CNPJ         string // Issuer's CNPJ (14 digits, unformatted)
+	Model        string // Document model: "55" for NF-e, "65" for NFC-e
+	Series       string // Series (3 digits)
+	Number       string // Invoice number (9 digits)
+	EmissionType string // Emission type (tpEmis), "1" for normal emission
+	Code         string // Random numeric code (cNF, 8 digits)
+	CheckDigit   byte   // Mod-11 check digit
+}
+
+// IsValidNFeKey checks if a string is a valid NF-e access key: 44 digits
+// whose last digit is the mod-11 check digit of the other 43 (weights 2 to
+// 9 from the right, remainders 0 and 1 giving 0). Spaces and other
+// formatting, as in keys printed on a DANFE, are ignored.
+//
+// Parameters:
+// - key: The access key to validate
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidNFeKey(key string) bool {
+	cleaned := cleanDigits(key)
+	if len(cleaned) != nfeKeyLength {
+		return false
+	}
+	return cleaned[nfeKeyLength-1] == nfeCheckDigit(cleaned[:nfeKeyLength-1])
+}
+
+// ParseNFeKey splits a valid NF-e access key into its fields
+//
+// Parameters:
+// - key: The access key to parse (formatted or unformatted)
+//
+// Returns:
+// - NFeKeyParts: The fields of the key
+// - error: ErrInvalidNFeKey if key is not a valid access key
+func ParseNFeKey(key string) (NFeKeyParts, error) {
+	if !IsValidNFeKey(key) {
+		return NFeKeyParts{}, fmt.Errorf("%w: %q", ErrInvalidNFeKey, key)
+	}
+
+	cleaned := cleanDigits(key)
+	return NFeKeyParts{
+		UF:           cleaned[0:2],
+		Year:         2000 + digitsValue(cleaned[2:4]),
+		Month:        digitsValue(cleaned[4:6]),
+		CNPJ:         cleaned[6:20],
+		Model:        cleaned[20:22],
+		Series:       cleaned[22:25],
+		Number:       cleaned[25:34],
+		EmissionType: cleaned[34:35],
+		Code:         cleaned[35:43],
+		CheckDigit:   cleaned[43],
+	}, nil
+}
+
+// Helper function to calculate the mod-11 check digit of an access key
+func nfeCheckDigit(digits string) byte {
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		if weight++; weight > 9 {
+			weight = 2
+		}
+	}
+
+	remainder := sum % 11
+	if remainder < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - remainder)
+}
+
+// Helper function to convert a string of decimal digits to an int
+func digitsValue(digits string) int {
+	n := 0
+	for i := 0; i < len(digits); i++ {
+		n = n*10 + int(digits[i]-'0')
+	}
+	return n
+}
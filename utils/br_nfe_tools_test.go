@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFeKeyValidation(t *testing.T) {
+	testCases := []struct {
+		key     string
+		isValid bool
+	}{
+		{"35240111222333000181550010000001231123456780", true},           // Valid key, remainder 0 or 1
+		{"41231261289038000124650020000045671837465915", true},           // Valid NFC-e key
+		{"3524 0111 2223 3300 0181 5500 1000 0001 2311 2345 6780", true}, // Formatted as on a DANFE
+		{"35240111222333000181550010000001231123456781", false},          // Wrong check digit
+		{"45240111222333000181550010000001231123456780", false},          // Altered digit
+		{"3524011122233300018155001000000123112345678", false},           // Too short
+		{"352401112223330001815500100000012311234567800", false},         // Too long
+		{"", false}, // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidNFeKey(tc.key))
+		})
+	}
+}
+
+func TestParseNFeKey(t *testing.T) {
+	parts, err := ParseNFeKey("3524 0111 2223 3300 0181 5500 1000 0001 2311 2345 6780")
+	assert.NoError(t, err)
+	assert.Equal(t, NFeKeyParts{
+		UF:           "35",
+		Year:         2024,
+		Month:        1,
+		CNPJ:         "11222333000181",
+		Model:        "55",
+		Series:       "001",
+		Number:       "000000123",
+		EmissionType: "1",
+		Code:         "12345678",
+		CheckDigit:   '0',
+	}, parts)
+	assert.True(t, IsValidCNPJ(parts.CNPJ))
+
+	_, err = ParseNFeKey("35240111222333000181550010000001231123456781")
+	assert.ErrorIs(t, err, ErrInvalidNFeKey)
+	_, err = ParseNFeKey("123")
+	assert.ErrorIs(t, err, ErrInvalidNFeKey)
+}
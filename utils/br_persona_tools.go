@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// syntheticEmailDomain is reserved for documentation and testing (RFC 2606),
+// so synthetic emails can never reach a real mailbox
+const syntheticEmailDomain = "example.com"
+
+// Common Brazilian given names and surnames used for synthetic personas
+var (
+	syntheticGivenNames = []string{
+		"Ana", "Antônio", "Beatriz", "Carlos", "Cecília", "Daniel", "Fernanda", "Francisco",
+		"Gabriel", "Helena", "João", "Júlia", "Lucas", "Luíza", "Marcos", "Maria",
+		"Mariana", "Paulo", "Pedro", "Rafaela", "Sebastião", "Sofia", "Thiago", "Vitória",
+	}
+	syntheticSurnames = []string{
+		"Almeida", "Alves", "Araújo", "Barbosa", "Cardoso", "Carvalho", "Costa", "Fernandes",
+		"Ferreira", "Gomes", "Lima", "Martins", "Melo", "Oliveira", "Pereira", "Ribeiro",
+		"Rocha", "Rodrigues", "Santos", "Silva", "Sousa", "Souza", "Conceição", "Gonçalves",
+	}
+)
+
+// accentReplacer strips the diacritics used in Portuguese names
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+)
+
+// Persona is a coherent synthetic person for test datasets. Every field is
+// fake: the CPF uses the synthetic prefix 999, the email the reserved
+// example.com domain, and the name is drawn from common Brazilian names.
+type Persona struct {
+	Name  string // Given name and two surnames, e.g. "Júlia Costa Ribeiro"
+	CPF   string // Valid synthetic CPF with prefix 999 (with formatting)
+	Email string // Address at example.com derived from the name
+	Phone string // Mobile number in E.164 format (+55DD9NNNNNNNN)
+	CEP   string // Postal code formatted as NNNNN-NNN
+}
+
+// GenerateSyntheticPersona creates a synthetic person whose documents all
+// pass their validators (IsValidCPF, NormalizePhoneBR, IsValidCEP), for
+// realistic integration tests without real personal data.
+//
+// Brazil has no reserved ranges for phone numbers or CEPs, so those are
+// random well-formed values that may belong to a real line or address; do
+// not call or mail them.
+//
+// Returns:
+// - Persona: The synthetic person
+// - error: Only returns error if random number generation fails
+func GenerateSyntheticPersona() (Persona, error) {
+	return GenerateSyntheticPersonaFromReader(rand.Reader)
+}
+
+// GenerateSyntheticPersonaFromReader is GenerateSyntheticPersona drawing all
+// of its random choices, the CPF included, from random instead of
+// crypto/rand, so tests can pass a deterministic reader and get reproducible
+// personas
+//
+// Parameters:
+// - random: Source of randomness
+//
+// Returns:
+// - Persona: The synthetic person
+// - error: Only returns error if reading from random fails
+func GenerateSyntheticPersonaFromReader(random io.Reader) (Persona, error) {
+	var picks [5]int
+	for i, n := range []int{len(syntheticGivenNames), len(syntheticSurnames), len(syntheticSurnames), len(validDDDs), 1000} {
+		var err error
+		if picks[i], err = randomInt(random, n); err != nil {
+			return Persona{}, err
+		}
+	}
+
+	given, middle, last := syntheticGivenNames[picks[0]], syntheticSurnames[picks[1]], syntheticSurnames[picks[2]]
+
+	cpf, err := GenerateSyntheticCPFFromReader(random)
+	if err != nil {
+		return Persona{}, err
+	}
+	subscriber, err := randomDigits(random, 8)
+	if err != nil {
+		return Persona{}, err
+	}
+	cep, err := randomCEP(random)
+	if err != nil {
+		return Persona{}, err
+	}
+
+	return Persona{
+		Name:  given + " " + middle + " " + last,
+		CPF:   cpf,
+		Email: fmt.Sprintf("%s.%s%03d@%s", emailLocalPart(given), emailLocalPart(last), picks[4], syntheticEmailDomain),
+		Phone: "+" + brazilCountryCode + sortedDDDs()[picks[3]] + "9" + subscriber,
+		CEP:   cep[:5] + "-" + cep[5:],
+	}, nil
+}
+
+// Helper function to turn a name into lowercase ASCII for an email address
+func emailLocalPart(name string) string {
+	return accentReplacer.Replace(strings.ToLower(name))
+}
+
+// Helper function to pick a random CEP at or above lowestCEP
+func randomCEP(random io.Reader) (string, error) {
+	const lowest = 1000000 // lowestCEP as a number
+	n, err := randomInt(random, 100000000-lowest)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08d", lowest+n), nil
+}
+
+// Helper function to generate n random decimal digits
+func randomDigits(random io.Reader, n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := randomInt(random, 10)
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d)
+	}
+	return string(digits), nil
+}
+
+// Helper function to pick a uniform random integer in [0, n)
+func randomInt(random io.Reader, n int) (int, error) {
+	v, err := rand.Int(random, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random number: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// Helper function to list the valid area codes in a stable order
+func sortedDDDs() []string {
+	ddds := make([]string, 0, len(validDDDs))
+	for ddd := range validDDDs {
+		ddds = append(ddds, ddd)
+	}
+	sort.Strings(ddds)
+	return ddds
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSyntheticPersona(t *testing.T) {
+	emailPattern := regexp.MustCompile(`^[a-z]+\.[a-z]+\d{3}@example\.com$`)
+
+	for i := 0; i < 100; i++ {
+		persona, err := GenerateSyntheticPersona()
+		assert.NoError(t, err)
+
+		assert.Len(t, strings.Fields(persona.Name), 3)
+		assert.True(t, IsValidCPF(persona.CPF), persona.CPF)
+		assert.True(t, strings.HasPrefix(persona.CPF, "999."), persona.CPF)
+		assert.Regexp(t, emailPattern, persona.Email)
+		assert.True(t, IsValidCEP(persona.CEP), persona.CEP)
+		assert.Regexp(t, `^\d{5}-\d{3}$`, persona.CEP)
+
+		normalized, err := NormalizePhoneBR(persona.Phone)
+		assert.NoError(t, err, persona.Phone)
+		assert.Equal(t, persona.Phone, normalized)
+
+		// The email is derived from the given name and last surname
+		names := strings.Fields(persona.Name)
+		assert.True(t, strings.HasPrefix(persona.Email, emailLocalPart(names[0])+"."+emailLocalPart(names[2])), persona.Email)
+	}
+}
+
+func TestGenerateSyntheticPersonaFromReader(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x5a, 0x3c, 0x91, 0x07, 0xe2}, 64)
+
+	// The same source yields the same persona
+	first, err := GenerateSyntheticPersonaFromReader(bytes.NewReader(seed))
+	assert.NoError(t, err)
+	second, err := GenerateSyntheticPersonaFromReader(bytes.NewReader(seed))
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.True(t, IsValidCPF(first.CPF), first.CPF)
+
+	_, err = GenerateSyntheticPersonaFromReader(failingReader{})
+	assert.Error(t, err)
+}
+
+func TestEmailLocalPart(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"João", "joao"},
+		{"Conceição", "conceicao"},
+		{"Antônio", "antonio"},
+		{"Silva", "silva"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, emailLocalPart(tc.name))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPhone is returned when a string is not a valid Brazilian phone number
+var ErrInvalidPhone = errors.New("invalid Brazilian phone number")
+
+// brazilCountryCode is the international dialing code for Brazil
+const brazilCountryCode = "55"
+
+// validDDDs lists the area codes (DDD) assigned by Anatel
+var validDDDs = map[string]bool{
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true,
+	"21": true, "22": true, "24": true, "27": true, "28": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "37": true, "38": true,
+	"41": true, "42": true, "43": true, "44": true, "45": true, "46": true, "47": true, "48": true, "49": true,
+	"51": true, "53": true, "54": true, "55": true,
+	"61": true, "62": true, "63": true, "64": true, "65": true, "66": true, "67": true, "68": true, "69": true,
+	"71": true, "73": true, "74": true, "75": true, "77": true, "79": true,
+	"81": true, "82": true, "83": true, "84": true, "85": true, "86": true, "87": true, "88": true, "89": true,
+	"91": true, "92": true, "93": true, "94": true, "95": true, "96": true, "97": true, "98": true, "99": true,
+}
+
+// NormalizePhoneBR converts a Brazilian phone number to E.164 (+55DDNNNNNNNNN)
+// It accepts formatted input such as "+55 11 98765-4321", "(11) 3265-4321"
+// or "011 98765-4321", and checks the area code (DDD) and number shape:
+// mobile numbers have 9 digits starting with 9, landlines 8 digits starting
+// with 2 to 5.
+//
+// Parameters:
+// - phone: The phone number to normalize
+//
+// Returns:
+// - string: The number in E.164 format
+// - error: ErrInvalidPhone if phone is not a valid Brazilian number
+func NormalizePhoneBR(phone string) (string, error) {
+	digits := cleanDigits(phone)
+
+	// Remove the trunk prefix (0) or the country code (55)
+	switch {
+	case strings.HasPrefix(digits, "0"):
+		digits = digits[1:]
+	case len(digits) > 11 && strings.HasPrefix(digits, brazilCountryCode):
+		digits = digits[len(brazilCountryCode):]
+	}
+
+	if len(digits) != 10 && len(digits) != 11 {
+		return "", ErrInvalidPhone
+	}
+
+	ddd, subscriber := digits[:2], digits[2:]
+	if !validDDDs[ddd] {
+		return "", fmt.Errorf("%w: unknown area code %s", ErrInvalidPhone, ddd)
+	}
+
+	switch len(subscriber) {
+	case 9: // Mobile
+		if subscriber[0] != '9' {
+			return "", fmt.Errorf("%w: mobile numbers must start with 9", ErrInvalidPhone)
+		}
+	case 8: // Landline
+		if subscriber[0] < '2' || subscriber[0] > '5' {
+			return "", fmt.Errorf("%w: landline numbers must start with 2 to 5", ErrInvalidPhone)
+		}
+	}
+
+	return "+" + brazilCountryCode + digits, nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhoneBR(t *testing.T) {
+	testCases := []struct {
+		phone    string
+		expected string
+	}{
+		{"+55 11 98765-4321", "+5511987654321"}, // Mobile with country code
+		{"(11) 98765-4321", "+5511987654321"},   // Mobile without country code
+		{"011 98765-4321", "+5511987654321"},    // Mobile with trunk prefix
+		{"5511987654321", "+5511987654321"},     // Unformatted with country code
+		{"+55 (21) 3265-4321", "+552132654321"}, // Landline with country code
+		{"2132654321", "+552132654321"},         // Unformatted landline
+		{"55 3265-4321", "+555532654321"},       // Landline in DDD 55
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.phone, func(t *testing.T) {
+			normalized, err := NormalizePhoneBR(tc.phone)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, normalized)
+		})
+	}
+}
+
+func TestNormalizePhoneBRInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"98765-4321",         // Missing DDD
+		"(20) 98765-4321",    // Unknown DDD
+		"(11) 88765-4321",    // 9-digit number not starting with 9
+		"(11) 8765-4321",     // Landline starting with 8
+		"+1 415 555 0100",    // Not a Brazilian number
+		"+55 11 987654-3210", // Too long
+	}
+
+	for _, phone := range invalid {
+		t.Run(phone, func(t *testing.T) {
+			_, err := NormalizePhoneBR(phone)
+			assert.ErrorIs(t, err, ErrInvalidPhone)
+		})
+	}
+}
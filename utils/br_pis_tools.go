@@ -0,0 +1,61 @@
+package utils
+
+import "fmt"
+
+// pisWeights are the check-digit weights for the first 10 PIS/PASEP digits
+var pisWeights = [10]int{3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// IsValidPIS checks if a string is a valid PIS/PASEP (also NIS/NIT) number
+// It removes formatting characters and validates the mod-11 check digit
+//
+// Parameters:
+// - pis: The PIS/PASEP string to validate (can include formatting like . and -)
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidPIS(pis string) bool {
+	// Remove all non-digit characters
+	cleaned := cleanDigits(pis)
+
+	// Check length (must be 11 digits)
+	if len(cleaned) != 11 {
+		return false
+	}
+
+	// Check for invalid patterns (all digits same)
+	if allDigitsSame(cleaned) {
+		return false
+	}
+
+	return cleaned[10] == calculatePISCheckDigit(cleaned[:10])
+}
+
+// FormatPIS formats a PIS/PASEP number as NNN.NNNNN.NN-N
+// Input that does not contain exactly 11 digits is returned unchanged
+//
+// Parameters:
+// - pis: The PIS/PASEP number (formatted or unformatted)
+//
+// Returns:
+// - string: The formatted PIS/PASEP number
+func FormatPIS(pis string) string {
+	cleaned := cleanDigits(pis)
+	if len(cleaned) != 11 {
+		return pis
+	}
+	return fmt.Sprintf("%s.%s.%s-%s", cleaned[:3], cleaned[3:8], cleaned[8:10], cleaned[10:])
+}
+
+// Helper function to calculate the PIS/PASEP check digit
+func calculatePISCheckDigit(partialPIS string) byte {
+	var sum int
+	for i, c := range partialPIS {
+		sum += int(c-'0') * pisWeights[i]
+	}
+
+	digit := 11 - (sum % 11)
+	if digit >= 10 {
+		return '0'
+	}
+	return byte('0' + digit)
+}
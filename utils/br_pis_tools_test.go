@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPISValidation(t *testing.T) {
+	testCases := []struct {
+		pis     string
+		isValid bool
+	}{
+		{"120.54400.24-8", true},  // Valid formatted PIS
+		{"12054400248", true},     // Valid unformatted PIS
+		{"170.12363.81-7", true},  // Valid formatted PIS
+		{"123.45678.90-0", true},  // Check digit 10 or 11 becomes 0
+		{"120.54400.24-9", false}, // Wrong check digit
+		{"111.11111.11-1", false}, // Invalid (all same digits)
+		{"", false},               // Empty
+		{"1205440024", false},     // Too short
+		{"120544002480", false},   // Too long
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pis, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidPIS(tc.pis))
+		})
+	}
+}
+
+func TestFormatPIS(t *testing.T) {
+	assert.Equal(t, "120.54400.24-8", FormatPIS("12054400248"))
+	assert.Equal(t, "120.54400.24-8", FormatPIS("120.54400.24-8"))
+	assert.Equal(t, "12345", FormatPIS("12345"))
+}
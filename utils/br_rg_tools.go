@@ -0,0 +1,101 @@
+package utils
+
+import "strings"
+
+// IsValidRG checks if a string is a valid RG (Registro Geral) number
+//
+// RG formats vary by issuing state: each state's Secretaria de Segurança
+// Pública defines its own length and check-digit rules. This implementation
+// targets the SSP-SP (São Paulo) format: 8 digits followed by a mod-11 check
+// digit computed with weights 2 to 9, where a result of 10 is written as X
+// and 11 as 0. RGs issued by other states may be reported as invalid.
+//
+// Parameters:
+// - rg: The RG string to validate (can include formatting like . and -)
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidRG(rg string) bool {
+	// Remove separators, keeping digits and the X check digit
+	cleaned := cleanRG(rg)
+
+	// Check length (must be 8 digits plus the check digit)
+	if len(cleaned) != 9 {
+		return false
+	}
+
+	body := cleaned[:8]
+	if cleanDigits(body) != body {
+		return false
+	}
+
+	// Check for invalid patterns (all digits same)
+	if allDigitsSame(body) {
+		return false
+	}
+
+	return cleaned[8] == calculateRGCheckDigit(body)
+}
+
+// FormatRG formats an RG in the SSP-SP layout (NN.NNN.NNN-D), with an
+// upper-case X check digit. Formatted and unformatted input are handled
+// alike; values that are not 8 digits plus a check digit are returned
+// unchanged. The check digit is not verified; use IsValidRG for that.
+//
+// Parameters:
+// - rg: The RG to format
+//
+// Returns:
+// - string: The formatted RG, or rg itself if it has an unexpected length
+func FormatRG(rg string) string {
+	cleaned, ok := splitRG(rg)
+	if !ok {
+		return rg
+	}
+	return formatRG(cleaned)
+}
+
+// Helper function to calculate the SSP-SP RG check digit
+func calculateRGCheckDigit(body string) byte {
+	var sum int
+	for i, c := range body {
+		sum += int(c-'0') * (i + 2)
+	}
+
+	digit := 11 - (sum % 11)
+	switch digit {
+	case 10:
+		return 'X'
+	case 11:
+		return '0'
+	default:
+		return byte('0' + digit)
+	}
+}
+
+// Helper function to clean an RG and check that it is 8 digits followed by
+// a digit or X
+func splitRG(rg string) (string, bool) {
+	cleaned := cleanRG(rg)
+	if len(cleaned) != 9 || cleanDigits(cleaned[:8]) != cleaned[:8] {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// Helper function to format a cleaned RG with SSP-SP punctuation
+func formatRG(rg string) string {
+	return rg[:2] + "." + rg[2:5] + "." + rg[5:8] + "-" + rg[8:]
+}
+
+// Helper function to remove separators from an RG, keeping digits and an
+// upper-cased X check digit
+func cleanRG(rg string) string {
+	var cleaned []rune
+	for _, c := range strings.ToUpper(rg) {
+		if (c >= '0' && c <= '9') || c == 'X' {
+			cleaned = append(cleaned, c)
+		}
+	}
+	return string(cleaned)
+}
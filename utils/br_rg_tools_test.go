@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRGValidation(t *testing.T) {
+	testCases := []struct {
+		rg      string
+		isValid bool
+	}{
+		{"24.678.131-2", true},  // Valid formatted RG
+		{"246781312", true},     // Valid unformatted RG
+		{"39.223.245-5", true},  // Valid formatted RG
+		{"51.620.466-X", true},  // Valid RG with X check digit
+		{"51.620.466-x", true},  // Lower-case X
+		{"20.000.002-0", true},  // Check digit 11 written as 0
+		{"51.620.466-0", false}, // X expected
+		{"24.678.131-3", false}, // Wrong check digit
+		{"11.111.111-1", false}, // Invalid (all same digits)
+		{"2467813X2", false},    // X outside the check digit
+		{"", false},             // Empty
+		{"24.678.131", false},   // Too short
+		{"2467813120", false},   // Too long
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rg, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidRG(tc.rg))
+		})
+	}
+}
+
+func TestFormatRG(t *testing.T) {
+	testCases := []struct {
+		rg       string
+		expected string
+	}{
+		{"246781312", "24.678.131-2"},    // Unformatted
+		{"24.678.131-2", "24.678.131-2"}, // Already formatted
+		{"51620466x", "51.620.466-X"},    // Lower-case X
+		{"2467813X2", "2467813X2"},       // X outside the check digit: unchanged
+		{"24.678.131", "24.678.131"},     // Too short: unchanged
+		{"", ""},                         // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rg, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatRG(tc.rg))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+// Título de eleitor layout (12 digits):
+//
+//	NNNNNNNN UU D1 D2
+//
+// - NNNNNNNN: sequential number
+// - UU: federative unit code, 01 (SP) to 28 (ZZ, voters abroad)
+// - D1: sum of the sequential digits weighted 2..9, modulo 11
+// - D2: sum of the UF digits weighted 7 and 8 plus D1 weighted 9, modulo 11
+//
+// A remainder of 10 becomes 0. For SP (01) and MG (02) a remainder of 0
+// becomes 1.
+const (
+	tituloUFSaoPaulo    = 1
+	tituloUFMinasGerais = 2
+	tituloUFMax         = 28
+)
+
+// IsValidTituloEleitor checks if a string is a valid título de eleitor
+// (Brazilian voter registration number)
+// It removes formatting characters, validates the UF code and both check digits
+//
+// Parameters:
+// - titulo: The título de eleitor string to validate (can include spaces and dots)
+//
+// Returns:
+// - bool: true if valid, false otherwise
+func IsValidTituloEleitor(titulo string) bool {
+	// Remove all non-digit characters
+	cleaned := cleanDigits(titulo)
+
+	// Check length (must be 12 digits)
+	if len(cleaned) != 12 {
+		return false
+	}
+
+	// Check for invalid patterns (all digits same)
+	if allDigitsSame(cleaned) {
+		return false
+	}
+
+	// Validate the UF code in the 9th and 10th digits
+	uf := int(cleaned[8]-'0')*10 + int(cleaned[9]-'0')
+	if uf < 1 || uf > tituloUFMax {
+		return false
+	}
+
+	// First check digit over the sequential number
+	var sum int
+	for i := 0; i < 8; i++ {
+		sum += int(cleaned[i]-'0') * (i + 2)
+	}
+	firstDigit := tituloCheckDigit(sum, uf)
+	if cleaned[10] != firstDigit {
+		return false
+	}
+
+	// Second check digit over the UF code and the first check digit
+	sum = int(cleaned[8]-'0')*7 + int(cleaned[9]-'0')*8 + int(firstDigit-'0')*9
+	return cleaned[11] == tituloCheckDigit(sum, uf)
+}
+
+// Helper function to reduce a weighted sum to a título de eleitor check digit
+func tituloCheckDigit(sum, uf int) byte {
+	digit := sum % 11
+	if digit == 10 {
+		return '0'
+	}
+	if digit == 0 && (uf == tituloUFSaoPaulo || uf == tituloUFMinasGerais) {
+		return '1'
+	}
+	return byte('0' + digit)
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTituloEleitorValidation(t *testing.T) {
+	testCases := []struct {
+		titulo  string
+		isValid bool
+	}{
+		{"1023 1234 0167", true},  // Valid SP (01), formatted with spaces
+		{"102312340167", true},    // Valid SP (01), unformatted
+		{"0043.5687.0906", true},  // Valid SC (09), formatted with dots
+		{"123456780396", true},    // Valid RJ (03)
+		{"000000060205", true},    // Valid MG (02)
+		{"415208302801", true},    // Valid ZZ (28), voter abroad
+		{"100000010116", true},    // SP: remainder 0 becomes 1
+		{"100000010302", true},    // RJ: remainder 0 stays 0
+		{"100000010106", false},   // SP with the non-SP/MG check digit
+		{"102312340168", false},   // Wrong second check digit
+		{"102312340267", false},   // Wrong first check digit
+		{"102312342967", false},   // Invalid UF code (29)
+		{"102312340067", false},   // Invalid UF code (00)
+		{"111111111111", false},   // Invalid (all same digits)
+		{"", false},               // Empty
+		{"10231234016", false},    // Too short
+		{"1023123401670", false},  // Too long
+		{"1023 1234 01a7", false}, // Letters are stripped, leaving 11 digits
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.titulo, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, IsValidTituloEleitor(tc.titulo))
+		})
+	}
+}
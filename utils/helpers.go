@@ -0,0 +1,12 @@
+package utils
+
+// Helper function to remove all non-digit characters from a document number
+func cleanDigits(value string) string {
+	var cleaned []rune
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			cleaned = append(cleaned, c)
+		}
+	}
+	return string(cleaned)
+}
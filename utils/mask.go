@@ -0,0 +1,106 @@
+package utils
+
+// Mask hides the middle of a value for display, keeping keepStart characters
+// at the beginning and keepEnd characters at the end and replacing the rest
+// with maskChar. Values shorter than keepStart+keepEnd are masked entirely.
+// Masking is not reversible and never involves the encryption key.
+//
+// Parameters:
+// - value: The value to mask
+// - keepStart: Number of leading characters to keep visible
+// - keepEnd: Number of trailing characters to keep visible
+// - maskChar: Character used to replace hidden characters
+//
+// Returns:
+// - string: The masked value, with the same number of characters as value
+func Mask(value string, keepStart, keepEnd int, maskChar rune) string {
+	runes := []rune(value)
+	if keepStart < 0 {
+		keepStart = 0
+	}
+	if keepEnd < 0 {
+		keepEnd = 0
+	}
+
+	// Too short to reveal anything safely: mask everything
+	if len(runes) < keepStart+keepEnd {
+		keepStart, keepEnd = 0, 0
+	}
+
+	for i := keepStart; i < len(runes)-keepEnd; i++ {
+		runes[i] = maskChar
+	}
+	return string(runes)
+}
+
+// MaskCPF masks a CPF for display, keeping the first three and last two
+// digits (e.g. 529.***.***-25). Input may be formatted or unformatted; values
+// that do not contain exactly 11 digits are masked entirely. The check digits
+// are not verified, so anything CPF-shaped is masked as a CPF; use
+// MaskCPFStrict when the field may hold something else.
+//
+// Parameters:
+// - cpf: The CPF to mask
+//
+// Returns:
+// - string: The masked CPF in standard punctuation
+func MaskCPF(cpf string) string {
+	cleaned := cleanCPF(cpf)
+	if len(cleaned) != 11 {
+		return Mask(cpf, 0, 0, '*')
+	}
+	return formatCPF(Mask(cleaned, 3, 2, '*'))
+}
+
+// MaskCPFStrict is like MaskCPF but first validates the CPF, so a value mapped
+// to the wrong field (say, a card number in a CPF column) is reported instead
+// of being masked as if it were a CPF. The error does not include the value.
+//
+// Parameters:
+// - cpf: The CPF to mask
+//
+// Returns:
+// - string: The masked CPF in standard punctuation
+// - error: ErrInvalidCPF if cpf is not a valid CPF
+func MaskCPFStrict(cpf string) (string, error) {
+	if !IsValidCPF(cpf) {
+		return "", ErrInvalidCPF
+	}
+	return MaskCPF(cpf), nil
+}
+
+// MaskCNPJ masks a CNPJ for display, keeping the first two digits of the root
+// and the four-digit branch visible (e.g. 11.***.***/0001-**). Input may be
+// formatted or unformatted; values that do not contain exactly 14 digits are
+// masked entirely, so a malformed value is never displayed in clear.
+//
+// Parameters:
+// - cnpj: The CNPJ to mask
+//
+// Returns:
+// - string: The masked CNPJ in standard punctuation
+func MaskCNPJ(cnpj string) string {
+	cleaned := cleanDigits(cnpj)
+	if len(cleaned) != 14 {
+		return Mask(cnpj, 0, 0, '*')
+	}
+	return formatCNPJ(cleaned[:2] + "******" + cleaned[8:12] + "**")
+}
+
+// MaskRG masks an RG for display, keeping the first two digits and the check
+// digit visible (e.g. 24.***.***-2). Input may be formatted or unformatted,
+// with an X check digit; values that are not 8 digits plus a check digit are
+// masked entirely.
+//
+// Parameters:
+// - rg: The RG to mask
+//
+// Returns:
+// - string: The masked RG in SSP-SP punctuation
+func MaskRG(rg string) string {
+	cleaned, ok := splitRG(rg)
+	if !ok {
+		return Mask(rg, 0, 0, '*')
+	}
+	return formatRG(Mask(cleaned, 2, 1, '*'))
+}
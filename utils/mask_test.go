@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask(t *testing.T) {
+	testCases := []struct {
+		value     string
+		keepStart int
+		keepEnd   int
+		expected  string
+	}{
+		{"1234567890", 2, 2, "12******90"},
+		{"1234567890", 0, 4, "******7890"},
+		{"1234567890", 4, 0, "1234******"},
+		{"12345", 3, 2, "12345"},           // Exactly keepStart+keepEnd
+		{"1234", 3, 2, "****"},             // Too short: mask everything
+		{"", 1, 1, ""},                     // Empty
+		{"João Silva", 1, 1, "J********a"}, // Multi-byte characters
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Mask(tc.value, tc.keepStart, tc.keepEnd, '*'))
+		})
+	}
+}
+
+func TestMaskCPF(t *testing.T) {
+	testCases := []struct {
+		cpf      string
+		expected string
+	}{
+		{"529.982.247-25", "529.***.***-25"}, // Formatted CPF
+		{"52998224725", "529.***.***-25"},    // Unformatted CPF
+		{"12345", "*****"},                   // Not a CPF: mask everything
+		{"", ""},                             // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cpf, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MaskCPF(tc.cpf))
+		})
+	}
+}
+
+func TestMaskCPFStrict(t *testing.T) {
+	testCases := []struct {
+		cpf      string
+		expected string
+		valid    bool
+	}{
+		{"529.982.247-25", "529.***.***-25", true}, // Formatted CPF
+		{"52998224725", "529.***.***-25", true},    // Unformatted CPF
+		{"52998224724", "", false},                 // Wrong check digit
+		{"11111111111", "", false},                 // Repeated digits
+		{"4111111111111111", "", false},            // Card number
+		{"", "", false},                            // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cpf, func(t *testing.T) {
+			masked, err := MaskCPFStrict(tc.cpf)
+			assert.Equal(t, tc.expected, masked)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidCPF)
+			}
+		})
+	}
+}
+
+func TestMaskCNPJ(t *testing.T) {
+	testCases := []struct {
+		cnpj     string
+		expected string
+	}{
+		{"11.222.333/0001-81", "11.***.***/0001-**"}, // Formatted CNPJ
+		{"11222333000181", "11.***.***/0001-**"},     // Unformatted CNPJ
+		{"1122233300018", "*************"},           // Not a CNPJ: mask everything
+		{"", ""},                                     // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cnpj, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MaskCNPJ(tc.cnpj))
+		})
+	}
+}
+
+func TestMaskRG(t *testing.T) {
+	testCases := []struct {
+		rg       string
+		expected string
+	}{
+		{"24.678.131-2", "24.***.***-2"}, // Formatted RG
+		{"246781312", "24.***.***-2"},    // Unformatted RG
+		{"51.620.466-x", "51.***.***-X"}, // X check digit
+		{"24.678.131", "**********"},     // Too short: mask everything
+		{"", ""},                         // Empty
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rg, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MaskRG(tc.rg))
+		})
+	}
+}
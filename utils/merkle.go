@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrInvalidMerkleInput is returned when a Merkle tree cannot be built from
+// the given hashes or index
+var ErrInvalidMerkleInput = errors.New("invalid Merkle tree input")
+
+// Domain separation prefixes of RFC 6962 (section 2.1), so that a leaf can
+// never be passed off as an interior node
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// MerkleRoot computes the Merkle tree hash of a batch of hex-encoded hashes,
+// such as the OriginalHash values of the records processed together, as
+// defined by RFC 6962: leaves are SHA-256(0x00 || hash) and interior nodes
+// SHA-256(0x01 || left || right), with the left subtree holding the largest
+// power of two leaves smaller than the batch. Publishing or signing the root
+// attests to the whole batch; MerkleProof then proves that a single record
+// was part of it without revealing the others.
+//
+// The Merkle helpers only accept hex: hashes from a Service configured with
+// another HashEncoding must be converted first, or produced with the default
+// HashEncodingHex.
+//
+// Parameters:
+// - hashes: Hex-encoded hashes, in batch order
+//
+// Returns:
+// - string: Hex-encoded root hash
+// - error: ErrInvalidMerkleInput if hashes is empty or not hex encoded
+func MerkleRoot(hashes []string) (string, error) {
+	leaves, err := merkleLeaves(hashes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(merkleTreeHash(leaves)), nil
+}
+
+// MerkleProof returns the audit path of the hash at index: the sibling
+// hashes needed to recompute the root from that hash alone, ordered from the
+// leaf up (RFC 6962, section 2.1.1)
+//
+// Parameters:
+// - hashes: Hex-encoded hashes, in batch order, as given to MerkleRoot
+// - index: Position of the hash to prove
+//
+// Returns:
+//   - []string: Hex-encoded audit path; empty for a batch of one
+//   - error: ErrInvalidMerkleInput if hashes is empty or not hex encoded, or
+//     index is out of range
+func MerkleProof(hashes []string, index int) ([]string, error) {
+	leaves, err := merkleLeaves(hashes)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("%w: index %d out of range for %d hashes", ErrInvalidMerkleInput, index, len(leaves))
+	}
+
+	path := merklePath(index, leaves)
+	proof := make([]string, len(path))
+	for i, node := range path {
+		proof[i] = hex.EncodeToString(node)
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof checks that hash is the entry at index of a batch of size
+// hashes with the given root, using an audit path from MerkleProof (RFC 9162,
+// section 2.1.3.2)
+//
+// Parameters:
+// - hash: Hex-encoded hash of the record, as given to MerkleRoot
+// - index: Position of the record in the batch
+// - size: Number of hashes in the batch
+// - proof: Hex-encoded audit path from MerkleProof
+// - root: Hex-encoded root from MerkleRoot
+//
+// Returns:
+// - bool: true if the proof is valid, false otherwise
+func VerifyMerkleProof(hash string, index, size int, proof []string, root string) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	leaf, err := hex.DecodeString(hash)
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(root)
+	if err != nil {
+		return false
+	}
+
+	node := merkleLeafHash(leaf)
+	fn, sn := uint64(index), uint64(size-1)
+	for _, encoded := range proof {
+		sibling, err := hex.DecodeString(encoded)
+		if err != nil || sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			node = merkleNodeHash(sibling, node)
+			// Skip the levels where this node has no right sibling
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			node = merkleNodeHash(node, sibling)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && subtle.ConstantTimeCompare(node, expected) == 1
+}
+
+// Helper function to decode the hashes of a batch
+func merkleLeaves(hashes []string) ([][]byte, error) {
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("%w: no hashes", ErrInvalidMerkleInput)
+	}
+	leaves := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		leaf, err := hex.DecodeString(hash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: hash %d is not hex encoded", ErrInvalidMerkleInput, i)
+		}
+		leaves[i] = leaf
+	}
+	return leaves, nil
+}
+
+// Helper function to compute the tree hash of a non-empty list of leaves
+func merkleTreeHash(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return merkleLeafHash(leaves[0])
+	}
+	k := merkleSplit(len(leaves))
+	return merkleNodeHash(merkleTreeHash(leaves[:k]), merkleTreeHash(leaves[k:]))
+}
+
+// Helper function to compute the audit path of leaf m
+func merklePath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := merkleSplit(len(leaves))
+	if m < k {
+		return append(merklePath(m, leaves[:k]), merkleTreeHash(leaves[k:]))
+	}
+	return append(merklePath(m-k, leaves[k:]), merkleTreeHash(leaves[:k]))
+}
+
+// Helper function to find the largest power of two smaller than n (n > 1)
+func merkleSplit(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// Helper function to hash a leaf
+func merkleLeafHash(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+// Helper function to hash two child nodes
+func merkleNodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// merkleTestLeaves are the leaves of the RFC 6962 reference test vectors
+var merkleTestLeaves = []string{
+	"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f",
+}
+
+func TestMerkleRoot(t *testing.T) {
+	testCases := []struct {
+		size int
+		root string
+	}{
+		{1, "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"},
+		{2, "fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125"},
+		{3, "aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77"},
+		{4, "d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7"},
+		{5, "4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4"},
+		{6, "76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef"},
+		{7, "ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c"},
+		{8, "5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprint(tc.size), func(t *testing.T) {
+			root, err := MerkleRoot(merkleTestLeaves[:tc.size])
+			assert.NoError(t, err)
+			assert.Equal(t, tc.root, root)
+		})
+	}
+
+	_, err := MerkleRoot(nil)
+	assert.ErrorIs(t, err, ErrInvalidMerkleInput)
+	_, err = MerkleRoot([]string{"00", "not hex"})
+	assert.ErrorIs(t, err, ErrInvalidMerkleInput)
+}
+
+func TestMerkleProof(t *testing.T) {
+	// Every leaf of every tree size proves against its root
+	for size := 1; size <= len(merkleTestLeaves); size++ {
+		hashes := merkleTestLeaves[:size]
+		root, err := MerkleRoot(hashes)
+		assert.NoError(t, err)
+
+		for index, hash := range hashes {
+			proof, err := MerkleProof(hashes, index)
+			assert.NoError(t, err)
+			assert.True(t, VerifyMerkleProof(hash, index, size, proof, root), "size %d index %d", size, index)
+
+			// A proof does not carry over to another record or position, nor extend
+			other := (index + 1) % size
+			if other != index {
+				assert.False(t, VerifyMerkleProof(hashes[other], index, size, proof, root))
+				assert.False(t, VerifyMerkleProof(hash, other, size, proof, root))
+			}
+			assert.False(t, VerifyMerkleProof(hash, index, size, append(proof, root), root))
+		}
+	}
+
+	// Reference audit path of leaf 0 in the tree of 8
+	proof, err := MerkleProof(merkleTestLeaves, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+		"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+		"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+	}, proof)
+
+	_, err = MerkleProof(merkleTestLeaves, 8)
+	assert.ErrorIs(t, err, ErrInvalidMerkleInput)
+	_, err = MerkleProof(merkleTestLeaves, -1)
+	assert.ErrorIs(t, err, ErrInvalidMerkleInput)
+	assert.False(t, VerifyMerkleProof("00", 0, 0, nil, "00"))
+	assert.False(t, VerifyMerkleProof("zz", 0, 1, nil, "00"))
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CPFRedaction is the text that replaces each CPF found by RedactCPFs
+const CPFRedaction = "[CPF REDACTED]"
+
+// cpfPattern matches CPF-shaped substrings, formatted (123.456.789-09) or not
+var cpfPattern = regexp.MustCompile(`\d{3}\.?\d{3}\.?\d{3}-?\d{2}`)
+
+// RedactCPFs replaces every valid CPF in free text with CPFRedaction
+// CPF-shaped substrings are only redacted if their check digits are valid and
+// they are not part of a longer digit sequence, so other numbers such as
+// order IDs or phone numbers are left untouched
+//
+// Parameters:
+// - text: Free text that may contain CPFs
+//
+// Returns:
+// - string: The text with valid CPFs redacted
+func RedactCPFs(text string) string {
+	redacted, _ := RedactCPFsCount(text)
+	return redacted
+}
+
+// RedactCPFsCount is like RedactCPFs but also reports how many CPFs were redacted
+//
+// Parameters:
+// - text: Free text that may contain CPFs
+//
+// Returns:
+// - string: The text with valid CPFs redacted
+// - int: Number of redactions made
+func RedactCPFsCount(text string) (string, int) {
+	var b strings.Builder
+	count, last := 0, 0
+
+	for _, loc := range cpfPattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+
+		// Skip matches embedded in a longer number
+		if (start > 0 && isDigit(text[start-1])) || (end < len(text) && isDigit(text[end])) {
+			continue
+		}
+		if !IsValidCPF(text[start:end]) {
+			continue
+		}
+
+		b.WriteString(text[last:start])
+		b.WriteString(CPFRedaction)
+		last = end
+		count++
+	}
+
+	if count == 0 {
+		return text, 0
+	}
+	b.WriteString(text[last:])
+	return b.String(), count
+}
+
+// Helper function to check if a byte is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactCPFs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+		count    int
+	}{
+		{"formatted", "Cliente 529.982.247-25 ligou", "Cliente [CPF REDACTED] ligou", 1},
+		{"unformatted", "cpf:52998224725.", "cpf:[CPF REDACTED].", 1},
+		{"several", "529.982.247-25 e 11144477735", "[CPF REDACTED] e [CPF REDACTED]", 2},
+		{"invalid check digits", "pedido 529.982.247-26", "pedido 529.982.247-26", 0},
+		{"embedded in longer number", "conta 1529982247250", "conta 1529982247250", 0},
+		{"phone number", "tel 11987654321", "tel 11987654321", 0},
+		{"all digits same", "id 111.111.111-11", "id 111.111.111-11", 0},
+		{"no digits", "sem documentos", "sem documentos", 0},
+		{"empty", "", "", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			redacted, count := RedactCPFsCount(tc.text)
+			assert.Equal(t, tc.expected, redacted)
+			assert.Equal(t, tc.count, count)
+			assert.Equal(t, tc.expected, RedactCPFs(tc.text))
+		})
+	}
+}